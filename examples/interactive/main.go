@@ -27,8 +27,8 @@ func main() {
 	go slackbot.GCCallback(15 * time.Minute)
 
 	bot.RegisterCallbackEvent(slackevents.AppMention, AppMentionEvent)
-	bot.RegisterInteractionCallback(slack.InteractionTypeBlockActions, "page_back", ActionShowPrev)
-	bot.RegisterInteractionCallback(slack.InteractionTypeBlockActions, "page_forward", ActionShowNext)
+	bot.RegisterBlockAction("", "page_back", ActionShowPrev)
+	bot.RegisterBlockAction("", "page_forward", ActionShowNext)
 
 	if *useSocket {
 		if err := bot.RunSocket(); err != nil {
@@ -76,10 +76,10 @@ func genBlocks(callback *slackbot.Callback) []slack.Block {
 
 		buttonBlocks := make([]slack.BlockElement, 0)
 
-		// Add a next button:
+		// Add a next button; its value carries the callback id:
 		PageForward := slack.NewButtonBlockElement(
-			callback.AddUUID().String(),
 			"page_forward",
+			callback.AddUUID().String(),
 			slack.NewTextBlockObject(
 				"plain_text",
 				fmt.Sprintf("Next %d", maxPerPage),
@@ -90,8 +90,8 @@ func genBlocks(callback *slackbot.Callback) []slack.Block {
 
 		// Add a previous button:
 		PageBackward := slack.NewButtonBlockElement(
-			callback.AddUUID().String(),
 			"page_back",
+			callback.AddUUID().String(),
 			slack.NewTextBlockObject(
 				"plain_text",
 				fmt.Sprintf("Previous %d", maxPerPage),
@@ -123,18 +123,18 @@ func genBlocks(callback *slackbot.Callback) []slack.Block {
 	return blocks
 }
 
-func ActionShowNext(payload slack.InteractionCallback, ctx *slackbot.Context) (retMsg slack.Message) {
-	return ActionShowMore(1, payload, ctx)
+func ActionShowNext(action slack.BlockAction, payload slack.InteractionCallback, ctx *slackbot.Context) (retMsg slack.Message) {
+	return ActionShowMore(1, action, ctx)
 }
-func ActionShowPrev(payload slack.InteractionCallback, ctx *slackbot.Context) (retMsg slack.Message) {
-	return ActionShowMore(-1, payload, ctx)
+func ActionShowPrev(action slack.BlockAction, payload slack.InteractionCallback, ctx *slackbot.Context) (retMsg slack.Message) {
+	return ActionShowMore(-1, action, ctx)
 }
 
-func ActionShowMore(offset int, payload slack.InteractionCallback, ctx *slackbot.Context) (retMsg slack.Message) {
+func ActionShowMore(offset int, action slack.BlockAction, ctx *slackbot.Context) (retMsg slack.Message) {
 
-	callback, err := slackbot.FindCallback(payload.ActionCallback.BlockActions[0].ActionID)
+	callback, err := slackbot.FindCallback(action.Value)
 	if err != nil {
-		log.Errorf("Callback id not found: %s", payload.ActionCallback.BlockActions[0].ActionID)
+		log.Errorf("Callback id not found: %s", action.Value)
 		return
 	}
 
@@ -159,12 +159,7 @@ func ActionShowMore(offset int, payload slack.InteractionCallback, ctx *slackbot
 
 	blocks := genBlocks(callback)
 
-	_, err = ctx.Api.PostEphemeral(
-		callback.GetString("channel"),
-		callback.GetString("user"),
-		slack.MsgOptionReplaceOriginal(payload.ResponseURL),
-		slack.MsgOptionBlocks(blocks...),
-	)
+	err = ctx.ReplaceOriginal(slack.NewBlockMessage(blocks...))
 	if err != nil {
 		log.Error(err.Error())
 	}
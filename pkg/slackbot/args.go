@@ -0,0 +1,332 @@
+package slackbot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type ArgType int
+
+const (
+	ArgString ArgType = iota
+	ArgInt
+	ArgBool
+	ArgDuration
+	ArgEnum
+	ArgUser
+	ArgChannel
+)
+
+// Param declares a positional argument or a flag of a command. Flags are
+// passed as --name=value or --name value; a bool flag may be passed as just
+// --name.
+type Param struct {
+	Name     string
+	Type     ArgType
+	Required bool
+	// Default is parsed like user input when the param is not given.
+	Default string
+	// Choices lists the accepted values of an ArgEnum.
+	Choices []string
+	// Description is shown in the generated help.
+	Description string
+}
+
+// Mention is a user (<@U123|name>) or channel (<#C123|name>) mention. Name is
+// empty when Slack did not include it.
+type Mention struct {
+	ID   string
+	Name string
+}
+
+var (
+	userMentionPattern    = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(?:\|([^>]*))?>$`)
+	channelMentionPattern = regexp.MustCompile(`^<#([CGD][A-Z0-9]+)(?:\|([^>]*))?>$`)
+)
+
+// Arg declares the next positional argument of the command.
+func (c *Command) Arg(param Param) *Command {
+	c.args = append(c.args, param)
+	return c
+}
+
+// Flag declares a flag of the command.
+func (c *Command) Flag(param Param) *Command {
+	c.flags = append(c.flags, param)
+	return c
+}
+
+func (c *Command) hasParams() bool {
+	return len(c.args) > 0 || len(c.flags) > 0
+}
+
+func (c *Command) findFlag(name string) (Param, bool) {
+
+	for _, flag := range c.flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+
+	return Param{}, false
+
+}
+
+// parseParams matches the words left after selecting c against its declared
+// arguments and flags.
+func (c *Command) parseParams(words []string) (*Params, error) {
+
+	params := &Params{values: make(map[string]interface{})}
+	positional := make([]string, 0, len(words))
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		if word == "--" {
+			positional = append(positional, words[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(word, "--") {
+			positional = append(positional, word)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(word[2:], "=")
+		flag, ok := c.findFlag(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown flag `--%s`", name)
+		}
+
+		if !hasValue {
+			if flag.Type == ArgBool {
+				value = "true"
+			} else if i+1 < len(words) {
+				i++
+				value = words[i]
+			} else {
+				return nil, fmt.Errorf("flag `--%s` needs a value", name)
+			}
+		}
+
+		parsed, err := flag.parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for `--%s`: %v", name, err)
+		}
+		params.values[name] = parsed
+	}
+
+	if len(positional) > len(c.args) {
+		return nil, fmt.Errorf("unexpected argument `%s`", positional[len(c.args)])
+	}
+
+	for i, arg := range c.args {
+		if i >= len(positional) {
+			break
+		}
+		parsed, err := arg.parse(positional[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for `<%s>`: %v", arg.Name, err)
+		}
+		params.values[arg.Name] = parsed
+	}
+
+	for _, arg := range c.args {
+		if err := params.applyDefault(arg, "<"+arg.Name+">"); err != nil {
+			return nil, err
+		}
+	}
+	for _, flag := range c.flags {
+		if err := params.applyDefault(flag, "--"+flag.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return params, nil
+
+}
+
+func (p Param) placeholder() string {
+	if p.Type == ArgEnum && len(p.Choices) > 0 {
+		return "<" + strings.Join(p.Choices, "|") + ">"
+	}
+	return "<" + p.Name + ">"
+}
+
+func (p Param) parse(value string) (interface{}, error) {
+
+	switch p.Type {
+	case ArgInt:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("`%s` is not a number", value)
+		}
+		return i, nil
+	case ArgBool:
+		switch strings.ToLower(value) {
+		case "1", "t", "true", "y", "yes", "on":
+			return true, nil
+		case "0", "f", "false", "n", "no", "off":
+			return false, nil
+		}
+		return nil, fmt.Errorf("`%s` is not a yes/no value", value)
+	case ArgDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("`%s` is not a duration like 90s or 1h30m", value)
+		}
+		return d, nil
+	case ArgEnum:
+		for _, choice := range p.Choices {
+			if strings.EqualFold(choice, value) {
+				return choice, nil
+			}
+		}
+		return nil, fmt.Errorf("`%s` is not one of %s", value, strings.Join(p.Choices, ", "))
+	case ArgUser:
+		m := userMentionPattern.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("`%s` is not a user mention like @name", value)
+		}
+		return Mention{ID: m[1], Name: m[2]}, nil
+	case ArgChannel:
+		m := channelMentionPattern.FindStringSubmatch(value)
+		if m == nil {
+			return nil, fmt.Errorf("`%s` is not a channel mention like #channel", value)
+		}
+		return Mention{ID: m[1], Name: m[2]}, nil
+	default:
+		return value, nil
+	}
+
+}
+
+// splitArgs splits a slash command's text into words. Single and double
+// quotes, including the typographic quotes Slack clients insert, group words
+// when they open a word or a flag value (--name="a b"), so apostrophes like in
+// "it's" are kept as is.
+func splitArgs(text string) ([]string, error) {
+
+	words := make([]string, 0)
+	var word strings.Builder
+	inWord := false
+	var closing, prev rune
+
+	for _, r := range text {
+		opening := !inWord || prev == '='
+		prev = r
+
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case opening && (r == '"' || r == '\''):
+			closing, inWord = r, true
+		case opening && r == '“':
+			closing, inWord = '”', true
+		case opening && r == '‘':
+			closing, inWord = '’', true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if closing != 0 {
+		return nil, fmt.Errorf("missing closing quote %c", closing)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+
+}
+
+// Params holds the parsed arguments and flags of a slash command, keyed by
+// their declared name. It is available as ctx.Params.
+type Params struct {
+	values map[string]interface{}
+}
+
+func (p *Params) applyDefault(param Param, label string) error {
+
+	if _, ok := p.values[param.Name]; ok {
+		return nil
+	}
+
+	if param.Default != "" {
+		parsed, err := param.parse(param.Default)
+		if err != nil {
+			return fmt.Errorf("invalid default for `%s`: %v", label, err)
+		}
+		p.values[param.Name] = parsed
+		return nil
+	}
+
+	if param.Required {
+		return fmt.Errorf("missing required `%s`", label)
+	}
+
+	return nil
+
+}
+
+func (p *Params) Get(name string) (value interface{}, ok bool) {
+
+	if p == nil {
+		return nil, false
+	}
+
+	value, ok = p.values[name]
+	return
+
+}
+
+func (p *Params) Has(name string) bool {
+	_, ok := p.Get(name)
+	return ok
+}
+
+func (p *Params) String(name string) string {
+	value, _ := p.Get(name)
+	s, _ := value.(string)
+	return s
+}
+
+func (p *Params) Int(name string) int {
+	value, _ := p.Get(name)
+	i, _ := value.(int)
+	return i
+}
+
+func (p *Params) Bool(name string) bool {
+	value, _ := p.Get(name)
+	b, _ := value.(bool)
+	return b
+}
+
+func (p *Params) Duration(name string) time.Duration {
+	value, _ := p.Get(name)
+	d, _ := value.(time.Duration)
+	return d
+}
+
+// Mention returns the value of an ArgUser or ArgChannel param.
+func (p *Params) Mention(name string) Mention {
+	value, _ := p.Get(name)
+	m, _ := value.(Mention)
+	return m
+}
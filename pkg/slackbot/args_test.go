@@ -0,0 +1,135 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"  create   app-x ", []string{"create", "app-x"}},
+		{`create "my app" 'x y'`, []string{"create", "my app", "x y"}},
+		{"create “my app”", []string{"create", "my app"}},
+		{`--note="two words" it's`, []string{"--note=two words", "it's"}},
+		{`""`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitArgs(tt.text)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := splitArgs(`create "my app`); err == nil {
+		t.Errorf("unterminated quote should fail")
+	}
+}
+
+func releaseCommand() *Command {
+	return newCommand("/release", nil).
+		Flag(Param{Name: "env", Type: ArgEnum, Choices: []string{"dev", "prod"}, Default: "dev"}).
+		Flag(Param{Name: "force", Type: ArgBool}).
+		Flag(Param{Name: "wait", Type: ArgDuration}).
+		Flag(Param{Name: "count", Type: ArgInt}).
+		Arg(Param{Name: "app", Required: true}).
+		Arg(Param{Name: "owner", Type: ArgUser}).
+		Arg(Param{Name: "channel", Type: ArgChannel})
+}
+
+func TestParseParams(t *testing.T) {
+	words, _ := splitArgs(`--env=PROD --force --wait 90s --count=3 app-x <@U123|jane> <#C42|general>`)
+
+	params, err := releaseCommand().parseParams(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := params.String("env"); got != "prod" {
+		t.Errorf("env: got %q", got)
+	}
+	if !params.Bool("force") {
+		t.Errorf("force should be set")
+	}
+	if got := params.Duration("wait"); got != 90*time.Second {
+		t.Errorf("wait: got %v", got)
+	}
+	if got := params.Int("count"); got != 3 {
+		t.Errorf("count: got %d", got)
+	}
+	if got := params.String("app"); got != "app-x" {
+		t.Errorf("app: got %q", got)
+	}
+	if got := params.Mention("owner"); got != (Mention{ID: "U123", Name: "jane"}) {
+		t.Errorf("owner: got %+v", got)
+	}
+	if got := params.Mention("channel"); got != (Mention{ID: "C42", Name: "general"}) {
+		t.Errorf("channel: got %+v", got)
+	}
+}
+
+func TestParseParamsDefaultsAndErrors(t *testing.T) {
+	params, err := releaseCommand().parseParams([]string{"app-x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := params.String("env"); got != "dev" {
+		t.Errorf("env default: got %q", got)
+	}
+	if params.Has("owner") {
+		t.Errorf("owner should not be set")
+	}
+
+	invalid := map[string]string{
+		"":                    "missing required `<app>`",
+		"--env=staging app-x": "is not one of dev, prod",
+		"--count=many app-x":  "is not a number",
+		"--colour=red app-x":  "unknown flag `--colour`",
+		"app-x @jane":         "is not a user mention",
+		"app-x <@U1> <#C1> x": "unexpected argument `x`",
+		"app-x --count":       "flag `--count` needs a value",
+	}
+	for text, want := range invalid {
+		words, _ := splitArgs(text)
+		_, err := releaseCommand().parseParams(words)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: got error %v, want it to contain %q", text, err, want)
+		}
+	}
+}
+
+func TestCommandValidationReply(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	called := false
+	bot.Command("/release").SubCommand("create").
+		Flag(Param{Name: "env", Type: ArgEnum, Choices: []string{"dev", "prod"}}).
+		Arg(Param{Name: "app", Required: true}).
+		Handle(func(command slack.SlashCommand, ctx *Context) slack.Message {
+			called = true
+			return slack.Message{Msg: slack.Msg{Text: ctx.Params.String("env") + " " + ctx.Params.String("app")}}
+		})
+
+	msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/release", Text: "create --env=qa app-x"}, &Context{})
+	if called {
+		t.Errorf("handler should not run on invalid input")
+	}
+	if msg.ResponseType != slack.ResponseTypeEphemeral || !strings.Contains(msg.Text, "Usage: `/release create [--env=<dev|prod>] <app>`") {
+		t.Errorf("unexpected reply: %+v", msg.Msg)
+	}
+
+	msg = bot.FireSlashCommand(slack.SlashCommand{Command: "/release", Text: "create --env prod app-x"}, &Context{})
+	if msg.Text != "prod app-x" {
+		t.Errorf("unexpected reply: %q", msg.Text)
+	}
+}
@@ -0,0 +1,308 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/slack-go/slack"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationErrors maps the block_id of an input block to the error shown
+// below it in the modal.
+type ValidationErrors map[string]string
+
+func (v ValidationErrors) Error() string {
+
+	blockIDs := make([]string, 0, len(v))
+	for blockID := range v {
+		blockIDs = append(blockIDs, blockID)
+	}
+	sort.Strings(blockIDs)
+
+	errors := make([]string, 0, len(v))
+	for _, blockID := range blockIDs {
+		errors = append(errors, fmt.Sprintf("%s: %s", blockID, v[blockID]))
+	}
+
+	return "invalid input: " + strings.Join(errors, ", ")
+
+}
+
+// Response returns the view submission response that shows the errors in the
+// modal.
+func (v ValidationErrors) Response() *slack.ViewSubmissionResponse {
+	return slack.NewErrorsViewSubmissionResponse(v)
+}
+
+// BindViewState decodes the values of a modal into the struct v points to.
+// Fields are bound with a `slack:"block_id/action_id"` tag and can be a
+// string (text inputs, single selects, radio buttons, date, time, user and
+// channel pickers), []string (multi-selects and checkboxes), bool (true when
+// any checkbox is checked), an int or float (number inputs) or time.Time
+// (datepicker or datetimepicker).
+//
+// A `validate` tag adds comma-separated rules: required, min=N and max=N (the
+// length of strings, the number of selected items or the value of numbers)
+// and regexp=EXPR, which must come last. Rules other than required skip
+// empty values, except min for selections. A `message` tag replaces the default
+// error message. When any rule fails, the returned error is a
+// ValidationErrors keyed by block_id:
+//
+//	type Signup struct {
+//		Email string `slack:"email/email_input" validate:"required,regexp=^.+@.+$" message:"Enter an email address"`
+//		Teams []string `slack:"teams/teams_select" validate:"min=1"`
+//	}
+//
+//	var signup Signup
+//	if err := slackbot.BindViewState(callback.View.State, &signup); err != nil {
+//		if errs, ok := err.(slackbot.ValidationErrors); ok {
+//			return errs.Response()
+//		}
+//		...
+//	}
+func BindViewState(state *slack.ViewState, v interface{}) error {
+
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target should be a pointer to a struct, got %T", v)
+	}
+	target = target.Elem()
+
+	errs := make(ValidationErrors)
+
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		tag, ok := field.Tag.Lookup("slack")
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		blockID, actionID, ok := strings.Cut(tag, "/")
+		if !ok {
+			return fmt.Errorf("field %s: slack tag should be \"block_id/action_id\", got %q", field.Name, tag)
+		}
+
+		rules, err := parseRules(field.Tag.Get("validate"))
+		if err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		var action slack.BlockAction
+		if state != nil {
+			action = state.Values[blockID][actionID]
+		}
+
+		problem, err := bindField(target.Field(i), action, rules)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		if problem != "" {
+			if message := field.Tag.Get("message"); message != "" {
+				problem = message
+			}
+			errs[blockID] = problem
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+
+}
+
+type bindRules struct {
+	required bool
+	min, max *float64
+	pattern  *regexp.Regexp
+}
+
+func parseRules(tag string) (rules bindRules, err error) {
+
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regexp=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			rules.required = true
+		case "min", "max":
+			limit, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return rules, fmt.Errorf("invalid %s rule %q", name, value)
+			}
+			if name == "min" {
+				rules.min = &limit
+			} else {
+				rules.max = &limit
+			}
+		case "regexp":
+			if rules.pattern, err = regexp.Compile(value); err != nil {
+				return rules, fmt.Errorf("invalid regexp rule: %v", err)
+			}
+		default:
+			return rules, fmt.Errorf("unknown validate rule %q", name)
+		}
+	}
+
+	return rules, nil
+
+}
+
+func actionString(action slack.BlockAction) string {
+
+	for _, value := range []string{
+		action.Value,
+		action.SelectedOption.Value,
+		action.SelectedDate,
+		action.SelectedTime,
+		action.SelectedUser,
+		action.SelectedConversation,
+		action.SelectedChannel,
+	} {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+
+}
+
+func actionStrings(action slack.BlockAction) []string {
+
+	if len(action.SelectedOptions) > 0 {
+		values := make([]string, 0, len(action.SelectedOptions))
+		for _, option := range action.SelectedOptions {
+			values = append(values, option.Value)
+		}
+		return values
+	}
+
+	for _, values := range [][]string{action.SelectedUsers, action.SelectedConversations, action.SelectedChannels} {
+		if len(values) > 0 {
+			return values
+		}
+	}
+
+	return []string{}
+
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// bindField sets field from action and returns the message of the first rule
+// that fails, if any.
+func bindField(field reflect.Value, action slack.BlockAction, rules bindRules) (string, error) {
+
+	switch {
+	case field.Type() == timeType:
+		var t time.Time
+		if action.SelectedDateTime != 0 {
+			t = time.Unix(action.SelectedDateTime, 0)
+		} else if action.SelectedDate != "" {
+			parsed, err := time.Parse(time.DateOnly, action.SelectedDate)
+			if err != nil {
+				return "Pick a valid date", nil
+			}
+			t = parsed
+		}
+		if t.IsZero() && rules.required {
+			return "Pick a date", nil
+		}
+		field.Set(reflect.ValueOf(t))
+		return "", nil
+
+	case field.Kind() == reflect.String:
+		value := actionString(action)
+		field.SetString(value)
+		if value == "" {
+			return requiredMessage(rules, "This field is required"), nil
+		}
+		length := float64(utf8.RuneCountInString(value))
+		if rules.min != nil && length < *rules.min {
+			return fmt.Sprintf("Enter at least %v characters", *rules.min), nil
+		}
+		if rules.max != nil && length > *rules.max {
+			return fmt.Sprintf("Enter at most %v characters", *rules.max), nil
+		}
+		if rules.pattern != nil && !rules.pattern.MatchString(value) {
+			return "Enter a valid value", nil
+		}
+		return "", nil
+
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		values := actionStrings(action)
+		field.Set(reflect.ValueOf(values).Convert(field.Type()))
+		if len(values) == 0 && rules.required {
+			return "Select at least one option", nil
+		}
+		// Unlike for text, min applies to an empty selection too: min=1
+		// means at least one option.
+		count := float64(len(values))
+		if rules.min != nil && count < *rules.min {
+			if *rules.min == 1 {
+				return "Select at least one option", nil
+			}
+			return fmt.Sprintf("Select at least %v options", *rules.min), nil
+		}
+		if rules.max != nil && count > *rules.max {
+			return fmt.Sprintf("Select at most %v options", *rules.max), nil
+		}
+		return "", nil
+
+	case field.Kind() == reflect.Bool:
+		checked := len(action.SelectedOptions) > 0
+		field.SetBool(checked)
+		if !checked {
+			return requiredMessage(rules, "This box must be checked"), nil
+		}
+		return "", nil
+
+	case field.CanInt(), field.CanFloat():
+		value := actionString(action)
+		if value == "" {
+			return requiredMessage(rules, "Enter a number"), nil
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || (field.CanInt() && number != float64(int64(number))) {
+			return "Enter a valid number", nil
+		}
+		if field.CanInt() {
+			if field.OverflowInt(int64(number)) {
+				return "Enter a smaller number", nil
+			}
+			field.SetInt(int64(number))
+		} else {
+			field.SetFloat(number)
+		}
+		if rules.min != nil && number < *rules.min {
+			return fmt.Sprintf("Enter a number of at least %v", *rules.min), nil
+		}
+		if rules.max != nil && number > *rules.max {
+			return fmt.Sprintf("Enter a number of at most %v", *rules.max), nil
+		}
+		return "", nil
+	}
+
+	return "", fmt.Errorf("unsupported field type %s", field.Type())
+
+}
+
+func requiredMessage(rules bindRules, message string) string {
+	if rules.required {
+		return message
+	}
+	return ""
+}
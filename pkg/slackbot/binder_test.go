@@ -0,0 +1,104 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type incidentForm struct {
+	Title    string    `slack:"title/title_input" validate:"required,min=3,max=20"`
+	Severity string    `slack:"severity/severity_select" validate:"required"`
+	Owner    string    `slack:"owner/owner_select"`
+	Teams    []string  `slack:"teams/teams_select" validate:"min=1,max=2"`
+	Notify   bool      `slack:"notify/notify_check"`
+	Impact   int       `slack:"impact/impact_input" validate:"min=1,max=5"`
+	Started  time.Time `slack:"started/started_date"`
+	Ticket   string    `slack:"ticket/ticket_input" validate:"regexp=^[A-Z]+-[0-9]+$" message:"Use a ticket key like ABC-123"`
+	internal string
+}
+
+func TestBindViewState(t *testing.T) {
+	state := &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+		"title":    {"title_input": {Value: "Database down"}},
+		"severity": {"severity_select": {SelectedOption: slack.OptionBlockObject{Value: "high"}}},
+		"owner":    {"owner_select": {SelectedUser: "U123"}},
+		"teams":    {"teams_select": {SelectedOptions: []slack.OptionBlockObject{{Value: "infra"}, {Value: "dba"}}}},
+		"notify":   {"notify_check": {SelectedOptions: []slack.OptionBlockObject{{Value: "yes"}}}},
+		"impact":   {"impact_input": {Value: "4"}},
+		"started":  {"started_date": {SelectedDate: "2026-10-18"}},
+		"ticket":   {"ticket_input": {Value: "OPS-42"}},
+	}}
+
+	var form incidentForm
+	if err := BindViewState(state, &form); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := incidentForm{
+		Title:    "Database down",
+		Severity: "high",
+		Owner:    "U123",
+		Teams:    []string{"infra", "dba"},
+		Notify:   true,
+		Impact:   4,
+		Started:  time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Ticket:   "OPS-42",
+	}
+	if !reflect.DeepEqual(form, want) {
+		t.Errorf("got %+v, want %+v", form, want)
+	}
+}
+
+func TestBindViewStateValidation(t *testing.T) {
+	state := &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+		"title":  {"title_input": {Value: "DB"}},
+		"teams":  {"teams_select": {SelectedOptions: []slack.OptionBlockObject{{Value: "a"}, {Value: "b"}, {Value: "c"}}}},
+		"impact": {"impact_input": {Value: "1.5"}},
+		"ticket": {"ticket_input": {Value: "ops 42"}},
+	}}
+
+	var form incidentForm
+	err := BindViewState(state, &form)
+
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	want := ValidationErrors{
+		"title":    "Enter at least 3 characters",
+		"severity": "This field is required",
+		"teams":    "Select at most 2 options",
+		"impact":   "Enter a valid number",
+		"ticket":   "Use a ticket key like ABC-123",
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("got %v, want %v", errs, want)
+	}
+
+	if response := errs.Response(); response.ResponseAction != slack.RAErrors || len(response.Errors) != len(want) {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	// min=1 on a selection also rejects an empty one.
+	delete(state.Values, "teams")
+	if errs, _ := BindViewState(state, &form).(ValidationErrors); errs["teams"] != "Select at least one option" {
+		t.Errorf("an empty selection should fail min=1, got %v", errs)
+	}
+}
+
+func TestBindViewStateInvalidTarget(t *testing.T) {
+	var form incidentForm
+	if err := BindViewState(nil, form); err == nil {
+		t.Errorf("binding to a non-pointer should fail")
+	}
+
+	var bad struct {
+		Field string `slack:"missing-action-id"`
+	}
+	if err := BindViewState(nil, &bad); err == nil {
+		t.Errorf("a tag without action_id should fail")
+	}
+}
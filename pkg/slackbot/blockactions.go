@@ -0,0 +1,197 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"strings"
+)
+
+// BlockActionRouting selects how block_actions payloads find the callbacks
+// registered with RegisterInteractionCallback.
+type BlockActionRouting int
+
+const (
+	// RouteByActionID uses the action_id of the action as callback id.
+	RouteByActionID BlockActionRouting = iota
+	// RouteByValue uses the value, or the value of the selected option, of
+	// the action as callback id. This was the only routing before
+	// RegisterBlockAction existed.
+	RouteByValue
+)
+
+// BlockActionFunc handles a single block action; action is the action of the
+// payload it was registered for.
+type BlockActionFunc func(action slack.BlockAction, callback slack.InteractionCallback, ctx *Context) slack.Message
+
+type blockActionKey struct {
+	blockID  string
+	actionID string
+}
+
+// SetBlockActionRouting sets how block_actions payloads are matched against
+// the callbacks registered with RegisterInteractionCallback. Handlers
+// registered with RegisterBlockAction are always matched first.
+func (s *SlackBot) SetBlockActionRouting(routing BlockActionRouting) {
+	s.blockActionRouting = routing
+}
+
+// RegisterBlockAction registers handler for block actions with the given
+// block_id and action_id. Leave one of them empty to match any value; a
+// registration for both ids takes precedence over one for the action_id only,
+// which takes precedence over one for the block_id only.
+func (s *SlackBot) RegisterBlockAction(blockID, actionID string, handler BlockActionFunc) error {
+
+	if blockID == "" && actionID == "" {
+		return fmt.Errorf("block action needs a block_id or action_id")
+	}
+
+	key := blockActionKey{blockID: blockID, actionID: actionID}
+	if _, ok := s.registeredBlockActions[key]; ok {
+		return fmt.Errorf("block action '%s/%s' already registered", blockID, actionID)
+	}
+
+	log.Debugf("Registering block action: %s/%s", blockID, actionID)
+	s.registeredBlockActions[key] = handler
+
+	return nil
+
+}
+
+func (s *SlackBot) findBlockAction(action *slack.BlockAction) (BlockActionFunc, bool) {
+
+	keys := []blockActionKey{
+		{blockID: action.BlockID, actionID: action.ActionID},
+		{actionID: action.ActionID},
+		{blockID: action.BlockID},
+	}
+
+	for _, key := range keys {
+		if handler, ok := s.registeredBlockActions[key]; ok {
+			return handler, true
+		}
+	}
+
+	return nil, false
+
+}
+
+// fireBlockActions dispatches every action of a block_actions payload, in
+// payload order, to the handler it matches. Each handler gets a copy of the
+// payload that only holds its own action. The responses are merged with
+// mergeMessages; ok is false when no action matched a handler.
+func (s *SlackBot) fireBlockActions(interactionCallback slack.InteractionCallback, ctx *Context) (payload slack.Message, ok bool) {
+
+	responses := make([]slack.Message, 0)
+
+	for _, action := range interactionCallback.ActionCallback.BlockActions {
+		// Params captured for a previous action do not apply to this one.
+		ctx.CallbackParams = nil
+
+		single := interactionCallback
+		single.ActionCallback = slack.ActionCallbacks{BlockActions: []*slack.BlockAction{action}}
+
+		if actionFunc, ok := s.findBlockAction(action); ok {
+			log.Debugf("Block action %s/%s found", action.BlockID, action.ActionID)
+			responses = append(responses, actionFunc(*action, single, ctx))
+			continue
+		}
+
+		callbackId := s.blockActionID(action)
+		if callbackFunc, ok := s.findInteractionCallback(interactionCallback.Type, callbackId, ctx); ok {
+			log.Debugf("Callback %s found", callbackId)
+			responses = append(responses, callbackFunc(single, ctx))
+		}
+	}
+
+	for _, action := range interactionCallback.ActionCallback.AttachmentActions {
+		ctx.CallbackParams = nil
+
+		single := interactionCallback
+		single.ActionCallback = slack.ActionCallbacks{AttachmentActions: []*slack.AttachmentAction{action}}
+
+		callbackId := s.attachmentActionID(action)
+		if callbackFunc, ok := s.findInteractionCallback(interactionCallback.Type, callbackId, ctx); ok {
+			log.Debugf("Callback %s found", callbackId)
+			responses = append(responses, callbackFunc(single, ctx))
+		}
+	}
+
+	return mergeMessages(responses), len(responses) > 0
+
+}
+
+// mergeMessages combines the responses of several handlers into one message:
+// texts are joined by newlines, blocks and attachments are concatenated in
+// order, the first response type is kept and replace/delete original are set
+// when any response sets them.
+func mergeMessages(messages []slack.Message) slack.Message {
+
+	var merged slack.Message
+	texts := make([]string, 0, len(messages))
+
+	for _, msg := range messages {
+		if isEmptyMessage(msg) {
+			continue
+		}
+		if msg.Text != "" {
+			texts = append(texts, msg.Text)
+		}
+		if merged.ResponseType == "" {
+			merged.ResponseType = msg.ResponseType
+		}
+		merged.Blocks.BlockSet = append(merged.Blocks.BlockSet, msg.Blocks.BlockSet...)
+		merged.Attachments = append(merged.Attachments, msg.Attachments...)
+		merged.ReplaceOriginal = merged.ReplaceOriginal || msg.ReplaceOriginal
+		merged.DeleteOriginal = merged.DeleteOriginal || msg.DeleteOriginal
+	}
+
+	merged.Text = strings.Join(texts, "\n")
+
+	return merged
+
+}
+
+// blockActionsCallbackID returns the callback id of the first action of a
+// block_actions payload, used when none of its actions has a handler.
+func (s *SlackBot) blockActionsCallbackID(interactionCallback slack.InteractionCallback) string {
+
+	callbackId := interactionCallback.Value
+
+	if actions := interactionCallback.ActionCallback.BlockActions; len(actions) > 0 {
+		log.Debugln("BlockActions")
+		callbackId = s.blockActionID(actions[0])
+	}
+
+	if actions := interactionCallback.ActionCallback.AttachmentActions; len(actions) > 0 {
+		log.Debugln("AttachmentActions")
+		callbackId = s.attachmentActionID(actions[0])
+	}
+
+	return callbackId
+
+}
+
+// blockActionID returns the callback id a block action is looked up with in
+// the registered interaction callbacks.
+func (s *SlackBot) blockActionID(action *slack.BlockAction) string {
+
+	if s.blockActionRouting != RouteByValue {
+		return action.ActionID
+	}
+
+	if action.Value == "" {
+		return action.SelectedOption.Value
+	}
+	return action.Value
+
+}
+
+func (s *SlackBot) attachmentActionID(action *slack.AttachmentAction) string {
+
+	if s.blockActionRouting != RouteByValue {
+		return action.Name
+	}
+	return action.Value
+
+}
@@ -0,0 +1,111 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"testing"
+)
+
+func blockActionsPayload(actions ...*slack.BlockAction) slack.InteractionCallback {
+	return slack.InteractionCallback{
+		Type:           slack.InteractionTypeBlockActions,
+		ActionCallback: slack.ActionCallbacks{BlockActions: actions},
+	}
+}
+
+func answer(text string) BlockActionFunc {
+	return func(action slack.BlockAction, callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: text + ":" + action.Value}}
+	}
+}
+
+func TestBlockActionPrecedence(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterBlockAction("", "approve", answer("action"))
+	bot.RegisterBlockAction("request", "approve", answer("block+action"))
+	bot.RegisterBlockAction("request", "", answer("block"))
+
+	tests := []struct {
+		blockID, actionID, want string
+	}{
+		{"request", "approve", "block+action:42"},
+		{"other", "approve", "action:42"},
+		{"request", "reject", "block:42"},
+	}
+
+	for _, tt := range tests {
+		payload := blockActionsPayload(&slack.BlockAction{BlockID: tt.blockID, ActionID: tt.actionID, Value: "42"})
+		if msg := bot.FireInteractiveCallback(payload, &Context{}); msg.Text != tt.want {
+			t.Errorf("%s/%s: got %q, want %q", tt.blockID, tt.actionID, msg.Text, tt.want)
+		}
+	}
+
+	if err := bot.RegisterBlockAction("", "", answer("any")); err == nil {
+		t.Errorf("registering without ids should fail")
+	}
+}
+
+func TestBlockActionCallbackRouting(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterInteractionCallback(slack.InteractionTypeBlockActions, "page_forward", func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: "forward"}}
+	})
+
+	byActionID := blockActionsPayload(&slack.BlockAction{ActionID: "page_forward", Value: "some-uuid"})
+	byValue := blockActionsPayload(&slack.BlockAction{ActionID: "some-uuid", Value: "page_forward"})
+
+	if msg := bot.FireInteractiveCallback(byActionID, &Context{}); msg.Text != "forward" {
+		t.Errorf("default routing should use the action_id, got %q", msg.Text)
+	}
+
+	bot.SetBlockActionRouting(RouteByValue)
+	if msg := bot.FireInteractiveCallback(byValue, &Context{}); msg.Text != "forward" {
+		t.Errorf("value routing should use the value, got %q", msg.Text)
+	}
+	if msg := bot.FireInteractiveCallback(byActionID, &Context{}); msg.Text != "" {
+		t.Errorf("value routing should not use the action_id, got %q", msg.Text)
+	}
+}
+
+func TestBlockActionsDispatchesEveryAction(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterBlockAction("", "first", answer("first"))
+	bot.RegisterInteractionCallback(slack.InteractionTypeBlockActions, "second", func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		actions := callback.ActionCallback.BlockActions
+		if len(actions) != 1 || actions[0].ActionID != "second" {
+			t.Errorf("handler should only get its own action, got %d actions", len(actions))
+		}
+		return slack.Message{Msg: slack.Msg{Text: "second:" + actions[0].Value, ReplaceOriginal: true}}
+	})
+
+	payload := blockActionsPayload(
+		&slack.BlockAction{ActionID: "first", Value: "1"},
+		&slack.BlockAction{ActionID: "unknown", Value: "2"},
+		&slack.BlockAction{ActionID: "second", Value: "3"},
+	)
+
+	msg := bot.FireInteractiveCallback(payload, &Context{})
+	if msg.Text != "first:1\nsecond:3" {
+		t.Errorf("unexpected merged text: %q", msg.Text)
+	}
+	if !msg.ReplaceOriginal {
+		t.Errorf("merged response should replace the original")
+	}
+}
+
+func TestBlockActionsResetCallbackParams(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterInteractionPattern(slack.InteractionTypeBlockActions, "approve:{id}", func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: "approve " + ctx.CallbackParam("id")}}
+	})
+	bot.RegisterBlockAction("", "comment", func(action slack.BlockAction, callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: "comment " + ctx.CallbackParam("id")}}
+	})
+
+	msg := bot.FireInteractiveCallback(blockActionsPayload(
+		&slack.BlockAction{ActionID: "approve:42"},
+		&slack.BlockAction{ActionID: "comment"},
+	), &Context{})
+	if msg.Text != "approve 42\ncomment " {
+		t.Errorf("params of the first action leaked into the second: %q", msg.Text)
+	}
+}
@@ -0,0 +1,275 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"strings"
+)
+
+// Command is a slash command, or one of its subcommands, in the command tree
+// of a SlackBot. Build the tree with SlackBot.Command:
+//
+//	bot.Command("/deploy").
+//		Sub("status", DeployStatus).
+//		Sub("rollback", DeployRollback)
+//	bot.Command("/deploy").SubCommand("env").Sub("list", EnvList)
+//
+// The words of command.Text select the subcommand; the words that are left
+// are available as ctx.Args in the handler. When the command declares
+// arguments or flags, those words are parsed into ctx.Params first and invalid
+// input is answered with an ephemeral error instead of calling the handler.
+//
+// A command that is documented (see Describe) or has subcommands or params
+// answers "/cmd help" with a generated help message, as does a command
+// without a handler of its own when it is invoked without words.
+type Command struct {
+	name        string
+	parent      *Command
+	handler     CommandFunc
+	subCommands map[string]*Command
+	subOrder    []string
+	args        []Param
+	flags       []Param
+	description string
+	usageText   string
+	examples    []string
+	async       bool
+	asyncAck    string
+	middleware  []Middleware
+}
+
+func newCommand(name string, parent *Command) *Command {
+	return &Command{
+		name:        name,
+		parent:      parent,
+		subCommands: make(map[string]*Command),
+	}
+}
+
+// Command returns the command tree for the given slash command, registering
+// it when needed. Like regexp.MustCompile, it panics when the command does not
+// start with a /, so a typo does not leave its handlers silently unused.
+func (s *SlackBot) Command(command string) *Command {
+
+	if cmd, ok := s.registeredCommands[command]; ok {
+		return cmd
+	}
+
+	if !strings.HasPrefix(command, "/") {
+		panic(fmt.Sprintf("slackbot: command '%s' should start with a /", command))
+	}
+
+	cmd := newCommand(command, nil)
+
+	log.Debugf("Registering command: %s", command)
+	s.registeredCommands[command] = cmd
+
+	return cmd
+
+}
+
+// Handle sets the handler that runs when no subcommand matches.
+func (c *Command) Handle(handler CommandFunc) *Command {
+	c.handler = handler
+	return c
+}
+
+// Sub adds a subcommand with the given handler and returns c, so several
+// subcommands can be chained.
+func (c *Command) Sub(name string, handler CommandFunc) *Command {
+	c.SubCommand(name).Handle(handler)
+	return c
+}
+
+// SubCommand returns the subcommand with the given name, adding it when
+// needed. Use it to build nested subcommands.
+func (c *Command) SubCommand(name string) *Command {
+
+	name = strings.ToLower(name)
+
+	if sub, ok := c.subCommands[name]; ok {
+		return sub
+	}
+
+	sub := newCommand(name, c)
+	c.subCommands[name] = sub
+	c.subOrder = append(c.subOrder, name)
+
+	return sub
+
+}
+
+// Async makes the command and its subcommands acknowledge Slack right away,
+// with ack as an ephemeral "working on it" message when it is not empty, and
+// run the handler in a goroutine. A non-empty message returned by the handler
+// is posted to the response_url; use ctx.Respond to post more follow-ups.
+func (c *Command) Async(ack string) *Command {
+	c.async = true
+	c.asyncAck = ack
+	return c
+}
+
+func (c *Command) isAsync() bool {
+	if c.async || c.parent == nil {
+		return c.async
+	}
+	return c.parent.isAsync()
+}
+
+func (c *Command) ackText() string {
+	if c.async || c.parent == nil {
+		return c.asyncAck
+	}
+	return c.parent.ackText()
+}
+
+func (c *Command) runAsync(command slack.SlashCommand, ctx *Context) {
+
+	if text := c.ackText(); text != "" {
+		ctx.acknowledge(ephemeralMessage(text))
+	} else {
+		ctx.acknowledge(nil)
+	}
+
+	// The request goroutine keeps using ctx after the ack, so the handler
+	// gets a finished copy of the context without the response writer.
+	detached := *ctx
+	detached.HTTPResponseWriter = nil
+	detached.Finish()
+	cancel := detached.detach()
+
+	go func() {
+		defer cancel()
+		defer func() {
+			if r := recover(); r != nil {
+				detached.bot.reportPanic(r, &Request{Kind: RequestCommand, Command: &command}, &detached)
+				detached.bot.respondPanic(&detached)
+			}
+		}()
+
+		payload := c.run(command, &detached)
+		if isEmptyMessage(payload) {
+			return
+		}
+		if err := detached.Respond(payload); err != nil {
+			log.Errorf("Could not respond to %s: %v", c.Path(), err)
+		}
+	}()
+
+}
+
+// run calls the handler of c inside the middleware of c and its parents.
+func (c *Command) run(command slack.SlashCommand, ctx *Context) slack.Message {
+	return WrapCommand(c.handler, c.commandMiddleware()...)(command, ctx)
+}
+
+// Path returns the full invocation of the command, e.g. "/deploy env list".
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.name
+	}
+	return c.parent.Path() + " " + c.name
+}
+
+// selected returns the (sub)command the words select, like dispatch does.
+func (c *Command) selected(args []string) *Command {
+	if len(args) > 0 {
+		if sub, ok := c.subCommands[strings.ToLower(args[0])]; ok {
+			return sub.selected(args[1:])
+		}
+	}
+	return c
+}
+
+func (c *Command) dispatch(command slack.SlashCommand, args []string, ctx *Context) slack.Message {
+
+	if len(args) > 0 {
+		if sub, ok := c.subCommands[strings.ToLower(args[0])]; ok {
+			return sub.dispatch(command, args[1:], ctx)
+		}
+		if strings.EqualFold(args[0], "help") && c.hasHelp() {
+			return c.helpMessage()
+		}
+	}
+
+	ctx.Args = args
+
+	if c.handler != nil && c.hasParams() {
+		params, err := c.parseParams(args)
+		if err != nil {
+			return ephemeralMessage(fmt.Sprintf("%s: %v\nUsage: `%s`", c.Path(), err, c.usageLine()))
+		}
+		ctx.Params = params
+	}
+
+	if c.handler != nil && c.isAsync() {
+		log.Debugln(c.Path(), " found, running async")
+		c.runAsync(command, ctx)
+		return slack.Message{}
+	}
+
+	if c.handler != nil {
+		log.Debugln(c.Path(), " found")
+		return c.run(command, ctx)
+	}
+
+	if len(args) > 0 {
+		return ephemeralMessage(fmt.Sprintf("Unknown subcommand `%s` for `%s`. Available subcommands: %s. Try `%s help`", args[0], c.Path(), c.subList(), c.Path()))
+	}
+
+	return c.helpMessage()
+
+}
+
+// usageLine returns the usage set with Usage, or generates one from the
+// subcommands, flags and arguments.
+func (c *Command) usageLine() string {
+
+	if c.usageText != "" {
+		return c.usageText
+	}
+
+	parts := []string{c.Path()}
+
+	if c.handler == nil && len(c.subOrder) > 0 {
+		parts = append(parts, "<"+strings.Join(c.subOrder, "|")+">")
+	}
+
+	for _, flag := range c.flags {
+		part := "--" + flag.Name
+		if flag.Type != ArgBool {
+			part += "=" + flag.placeholder()
+		}
+		if !flag.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+
+	for _, arg := range c.args {
+		part := arg.placeholder()
+		if !arg.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, " ")
+
+}
+
+func ephemeralMessage(text string) slack.Message {
+	return slack.Message{Msg: slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: text}}
+}
+
+func (c *Command) subList() string {
+
+	names := make([]string, 0, len(c.subOrder))
+	for _, name := range c.subOrder {
+		names = append(names, fmt.Sprintf("`%s`", name))
+	}
+
+	return strings.Join(names, ", ")
+
+}
@@ -0,0 +1,279 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reply(text string) CommandFunc {
+	return func(command slack.SlashCommand, ctx *Context) slack.Message {
+		return slack.Message{Msg: slack.Msg{Text: text + ":" + strings.Join(ctx.Args, ",")}}
+	}
+}
+
+func TestCommandRouting(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+
+	bot.Command("/deploy").
+		Handle(reply("default")).
+		Sub("status", reply("status")).
+		Sub("rollback", reply("rollback"))
+	bot.Command("/deploy").SubCommand("env").Sub("list", reply("env list"))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", "default:"},
+		{"status", "status:"},
+		{"STATUS app-x", "status:app-x"},
+		{"rollback app-x v2", "rollback:app-x,v2"},
+		{"env list", "env list:"},
+		{"unknown words", "default:unknown,words"},
+	}
+
+	for _, tt := range tests {
+		msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: tt.text}, &Context{})
+		if msg.Text != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.text, msg.Text, tt.want)
+		}
+	}
+}
+
+func TestCommandUnknownSubcommand(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.Command("/deploy").Sub("status", reply("status"))
+
+	msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: "launch"}, &Context{})
+	if !strings.Contains(msg.Text, "Unknown subcommand `launch`") || !strings.Contains(msg.Text, "`status`") {
+		t.Errorf("unexpected reply: %q", msg.Text)
+	}
+	if msg.ResponseType != slack.ResponseTypeEphemeral {
+		t.Errorf("reply should be ephemeral, got %q", msg.ResponseType)
+	}
+}
+
+func TestRegisterCommandTwice(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+
+	if err := bot.RegisterCommand("/hello", reply("hello")); err != nil {
+		t.Fatalf("first registration failed: %v", err)
+	}
+	if err := bot.RegisterCommand("/hello", reply("hello")); err == nil {
+		t.Errorf("second registration should fail")
+	}
+}
+
+func TestCommandWithoutSlash(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+
+	if err := bot.RegisterCommand("hello", reply("hello")); err == nil {
+		t.Errorf("RegisterCommand should refuse a command without a /")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Command should panic on a command without a /")
+		}
+	}()
+	bot.Command("deploy").Sub("status", reply("status"))
+}
+
+func helpText(msg slack.Message) string {
+	texts := make([]string, 0)
+	for _, block := range msg.Blocks.BlockSet {
+		texts = append(texts, block.(*slack.SectionBlock).Text.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestCommandHelp(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.Command("/deploy").
+		Describe("Deploy applications").
+		Example("/deploy status app-x").
+		Sub("status", reply("status"))
+	bot.Command("/deploy").SubCommand("status").
+		Describe("Show the deploy status").
+		Arg(Param{Name: "app", Description: "application name"})
+	bot.RegisterCommand("/slap", reply("slap"))
+	bot.RegisterHelpCommand("/help")
+
+	for _, text := range []string{"", "help", "HELP"} {
+		help := helpText(bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: text}, &Context{}))
+		for _, want := range []string{"Deploy applications", "`/deploy <status>`", "`/deploy status` – Show the deploy status", "`/deploy status app-x`"} {
+			if !strings.Contains(help, want) {
+				t.Errorf("%q: help %q should contain %q", text, help, want)
+			}
+		}
+	}
+
+	help := helpText(bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: "status help"}, &Context{}))
+	if !strings.Contains(help, "`/deploy status [<app>]`") || !strings.Contains(help, "`<app>` – application name") {
+		t.Errorf("unexpected subcommand help: %q", help)
+	}
+
+	if msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/slap", Text: "help"}, &Context{}); msg.Text != "slap:help" {
+		t.Errorf("undocumented command should get help as argument, got %q", msg.Text)
+	}
+
+	list := helpText(bot.FireSlashCommand(slack.SlashCommand{Command: "/help"}, &Context{}))
+	if !strings.Contains(list, "`/deploy` – Deploy applications") || !strings.Contains(list, "• `/slap`") {
+		t.Errorf("unexpected command list: %q", list)
+	}
+}
+
+func TestCommandAsync(t *testing.T) {
+	server, received := webhookServer(t)
+
+	bot := NewSlackBot("", "", "")
+	bot.Command("/report").Async("Working on it…").Handle(func(command slack.SlashCommand, ctx *Context) slack.Message {
+		if err := ctx.Respond(slack.Message{Msg: slack.Msg{Text: "halfway"}}); err != nil {
+			t.Errorf("respond failed: %v", err)
+		}
+		return slack.Message{Msg: slack.Msg{ResponseType: slack.ResponseTypeInChannel, Text: "done"}}
+	})
+
+	ctx := &Context{}
+	msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/report", ResponseURL: server.URL}, ctx)
+	if !isEmptyMessage(msg) || !ctx.IsFinished() {
+		t.Errorf("async command should be acknowledged by the framework, got %+v", msg.Msg)
+	}
+
+	for _, want := range []string{"halfway", "done"} {
+		select {
+		case got := <-received:
+			if got.Text != want {
+				t.Errorf("got follow-up %q, want %q", got.Text, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no follow-up %q received", want)
+		}
+	}
+}
+
+func TestCommandAsyncContext(t *testing.T) {
+	server, received := webhookServer(t)
+
+	bot := NewSlackBot(testSigningSecret, "", "")
+	bot.Command("/report").Async("").Use(func(next Handler) Handler {
+		return func(req *Request, ctx *Context) interface{} {
+			ctx.SetValue(userKey{}, "U1")
+			return next(req, ctx)
+		}
+	}).Handle(func(command slack.SlashCommand, ctx *Context) slack.Message {
+		ctx.Finish()
+		if ctx.HTTPResponseWriter != nil {
+			t.Errorf("async handlers should not get the response writer")
+		}
+		user, _ := ctx.Value(userKey{}).(string)
+		return slack.Message{Msg: slack.Msg{Text: user}}
+	})
+
+	form := url.Values{"command": {"/report"}, "response_url": {server.URL}}
+	w := httptest.NewRecorder()
+	bot.CommandsHandler(w, signedRequest("/slack/commands", form))
+	if w.Code != http.StatusOK {
+		t.Errorf("async command should be acknowledged, got %d", w.Code)
+	}
+
+	select {
+	case got := <-received:
+		if got.Text != "U1" {
+			t.Errorf("got follow-up %q", got.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no follow-up received")
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.Command("/deploy").
+		SubE("app", func(command slack.SlashCommand, ctx *Context) (slack.Message, error) {
+			return slack.Message{}, UserErrorf("Unknown app %s", strings.Join(ctx.Args, " "))
+		}).
+		SubE("env", func(command slack.SlashCommand, ctx *Context) (slack.Message, error) {
+			return slack.Message{}, fmt.Errorf("connection refused")
+		})
+
+	msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: "app x"}, &Context{bot: bot})
+	if msg.Text != "Unknown app x" || msg.ResponseType != slack.ResponseTypeEphemeral {
+		t.Errorf("user error should be shown as is, got %+v", msg.Msg)
+	}
+
+	msg = bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: "env"}, &Context{bot: bot})
+	if strings.Contains(msg.Text, "connection refused") || !strings.Contains(msg.Text, "error id") {
+		t.Errorf("internal error should be hidden behind an error id, got %q", msg.Text)
+	}
+
+	bot.SetErrorRenderer(func(err error, errorID string, req *Request, ctx *Context) slack.Message {
+		return ephemeralMessage(req.Name() + " failed: " + err.Error())
+	})
+	msg = bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: "env"}, &Context{bot: bot})
+	if msg.Text != "/deploy failed: connection refused" {
+		t.Errorf("custom renderer not used, got %q", msg.Text)
+	}
+}
+
+func TestNotFoundHandlers(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterInteractionCallback(slack.InteractionTypeShortcut, "known", func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return slack.Message{}
+	})
+
+	if msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/nope", Text: "x"}, &Context{}); msg.Text != "Unknown command: /nope x" {
+		t.Errorf("default command reply changed: %q", msg.Text)
+	}
+	if msg := bot.fireInteractiveCallback(slack.InteractionCallback{Type: slack.InteractionTypeShortcut, CallbackID: "other"}, &Context{}); !isEmptyMessage(msg) {
+		t.Errorf("unknown id of a known type should get an empty reply, got %q", msg.Text)
+	}
+
+	bot.SetCommandNotFound(func(command slack.SlashCommand, ctx *Context) slack.Message {
+		return ephemeralMessage("Try /help instead of " + command.Command)
+	})
+	bot.SetInteractionNotFound(func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return ephemeralMessage("expired: " + callback.CallbackID)
+	})
+	events := 0
+	bot.SetEventNotFound(func(event slackevents.EventsAPIEvent, ctx *Context) {
+		events++
+	})
+
+	if msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/nope"}, &Context{}); msg.Text != "Try /help instead of /nope" {
+		t.Errorf("command not found handler not used: %q", msg.Text)
+	}
+	for _, interactionType := range []slack.InteractionType{slack.InteractionTypeShortcut, slack.InteractionTypeMessageAction} {
+		msg := bot.fireInteractiveCallback(slack.InteractionCallback{Type: interactionType, CallbackID: "other"}, &Context{})
+		if msg.Text != "expired: other" {
+			t.Errorf("%s: interaction not found handler not used: %q", interactionType, msg.Text)
+		}
+	}
+	bot.FireCallbackEvent(appMention(), &Context{})
+	if events != 1 {
+		t.Errorf("event not found handler called %d times", events)
+	}
+}
+
+func TestCommandUnbalancedQuote(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterCommand("/note", reply("note"))
+	bot.Command("/remind").Sub("add", reply("add")).SubCommand("add").Arg(Param{Name: "text"})
+
+	msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/note", Text: `he said "hi`}, &Context{})
+	if msg.Text != `note:he,said,"hi` {
+		t.Errorf("command without params should get the words as typed, got %q", msg.Text)
+	}
+
+	msg = bot.FireSlashCommand(slack.SlashCommand{Command: "/remind", Text: `add "buy milk`}, &Context{})
+	if !strings.Contains(msg.Text, "missing closing quote") {
+		t.Errorf("command with params should report the quote, got %q", msg.Text)
+	}
+}
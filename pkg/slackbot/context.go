@@ -1,9 +1,11 @@
 package slackbot
 
 import (
+	"context"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
 	"net/http"
+	"time"
 )
 
 const (
@@ -11,14 +13,37 @@ const (
 	SLACK_CONTEXT_SOCKET = 2
 )
 
+// socketAckTimeout is the time Slack gives to acknowledge a socket mode event.
+const socketAckTimeout = 3 * time.Second
+
 type Context struct {
 	Type               int
 	Api                *slack.Client
+	bot                *SlackBot
 	isFinished         bool
+	responseURL        string
+	triggerID          string
 	HTTPRequest        *http.Request
 	HTTPResponseWriter http.ResponseWriter
 	Socket             *socketmode.Client
 	Event              *socketmode.Event
+	ctx                context.Context
+	release            context.CancelFunc
+	// queue is set for events handled by the async event queue.
+	queue *eventQueue
+
+	// Args holds the words of a slash command's text that are left after
+	// selecting the (sub)command.
+	Args []string
+	// Params holds the parsed arguments and flags of a slash command that
+	// declares them.
+	Params *Params
+	// CallbackParams holds the parameters captured by the callback id pattern
+	// of the interaction callback being handled.
+	CallbackParams map[string]string
+	// Matches holds the match and submatches of the Hears pattern of the
+	// message being handled.
+	Matches []string
 }
 
 func (c Context) IsHTTP() bool {
@@ -28,6 +53,12 @@ func (c Context) IsSocket() bool {
 	return c.Type == SLACK_CONTEXT_SOCKET
 }
 
+// CallbackParam returns the named parameter captured by the callback id
+// pattern, or an empty string.
+func (c Context) CallbackParam(name string) string {
+	return c.CallbackParams[name]
+}
+
 func (c Context) IsFinished() bool {
 	return c.isFinished
 }
@@ -40,20 +71,113 @@ func (c *Context) Ack(req socketmode.Request, payload ...interface{}) {
 	c.Socket.Ack(req, payload...)
 }
 
-func (s *SlackBot) newHTTPContext(w http.ResponseWriter, r *http.Request) (context *Context) {
-	context = &Context{}
-	context.Type = SLACK_CONTEXT_HTTP
-	context.Api = s.api
-	context.HTTPRequest = r
-	context.HTTPResponseWriter = w
+// acknowledge sends payload as the HTTP response or socket ack right away and
+// finishes the context. It does nothing when the context is already finished.
+func (c *Context) acknowledge(payload interface{}) {
+
+	if c.IsFinished() {
+		return
+	}
+
+	switch {
+	case c.IsHTTP() && payload == nil:
+		c.HTTPResponseWriter.WriteHeader(http.StatusOK)
+	case c.IsHTTP():
+		c.bot.renderJSON(c.HTTPResponseWriter, c.HTTPRequest, payload)
+	case c.IsSocket():
+		c.Socket.Ack(*c.Event.Request, payload)
+	}
+
+	c.Finish()
+
+}
+
+func (s *SlackBot) newHTTPContext(w http.ResponseWriter, r *http.Request) (ctx *Context) {
+	ctx = &Context{}
+	ctx.Type = SLACK_CONTEXT_HTTP
+	ctx.Api = s.api
+	ctx.bot = s
+	ctx.HTTPRequest = r
+	ctx.HTTPResponseWriter = w
+	ctx.ctx, ctx.release = s.withShutdown(r.Context())
 	return
 }
 
-func (s *SlackBot) newSocketContext(event *socketmode.Event) (context *Context) {
-	context = &Context{}
-	context.Type = SLACK_CONTEXT_SOCKET
-	context.Api = s.api
-	context.Socket = s.socket
-	context.Event = event
+func (s *SlackBot) newSocketContext(event *socketmode.Event) (ctx *Context) {
+	ctx = &Context{}
+	ctx.Type = SLACK_CONTEXT_SOCKET
+	ctx.Api = s.api
+	ctx.bot = s
+	ctx.Socket = s.socket
+	ctx.Event = event
+	ctx.ctx, ctx.release = context.WithTimeout(s.shutdownContext(), socketAckTimeout)
 	return
 }
+
+// Context returns the context.Context of the request. It is derived from the
+// HTTP request, or in socket mode has a deadline matching the time Slack
+// gives to acknowledge an event. It is cancelled when the request is done
+// and when the bot shuts down; async commands and events get a context that
+// lasts until their handler returns. Pass it to Slack API calls and
+// downstream services:
+//
+//	ctx.Api.PostMessageContext(ctx.Context(), channel, options...)
+func (c *Context) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// SetValue stores value under key in the context.Context of the request, like
+// context.WithValue, e.g. for data a middleware provides to the handlers.
+// Set values before handlers run concurrently.
+func (c *Context) SetValue(key, value interface{}) {
+	c.ctx = context.WithValue(c.Context(), key, value)
+}
+
+// Value returns the value stored under key with SetValue, or by the parent
+// context.
+func (c *Context) Value(key interface{}) interface{} {
+	return c.Context().Value(key)
+}
+
+// done cancels the context of the request once it is handled.
+func (c *Context) done() {
+	if c.release != nil {
+		c.release()
+	}
+}
+
+// detach gives c a context that is not cancelled when the request is done,
+// for work that continues after the request is acknowledged. It keeps the
+// values of the request. Call the returned function when the work is done.
+func (c *Context) detach() context.CancelFunc {
+
+	var cancel context.CancelFunc
+	c.ctx, cancel = c.bot.withShutdown(context.WithoutCancel(c.Context()))
+
+	return cancel
+
+}
+
+// shutdownContext returns the context that is cancelled by Shutdown.
+func (s *SlackBot) shutdownContext() context.Context {
+	if s == nil || s.baseContext == nil {
+		return context.Background()
+	}
+	return s.baseContext
+}
+
+// withShutdown returns a copy of parent that is also cancelled by Shutdown.
+func (s *SlackBot) withShutdown(parent context.Context) (context.Context, context.CancelFunc) {
+
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(s.shutdownContext(), cancel)
+
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+
+}
@@ -0,0 +1,63 @@
+package slackbot
+
+import (
+	"context"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type userKey struct{}
+
+func TestContextValues(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.Use(func(next Handler) Handler {
+		return func(req *Request, ctx *Context) interface{} {
+			ctx.SetValue(userKey{}, "U1")
+			return next(req, ctx)
+		}
+	})
+	bot.RegisterCommand("/whoami", func(command slack.SlashCommand, ctx *Context) slack.Message {
+		user, _ := ctx.Value(userKey{}).(string)
+		return slack.Message{Msg: slack.Msg{Text: user}}
+	})
+
+	ctx := bot.newHTTPContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/slack/commands", nil))
+	defer ctx.done()
+	if msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/whoami"}, ctx); msg.Text != "U1" {
+		t.Errorf("handler should see the middleware value, got %q", msg.Text)
+	}
+}
+
+func TestContextLifetime(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+
+	socketCtx := bot.newSocketContext(&socketmode.Event{})
+	deadline, ok := socketCtx.Context().Deadline()
+	if !ok || time.Until(deadline) > socketAckTimeout {
+		t.Errorf("socket context should have a deadline within the ack window")
+	}
+
+	httpCtx := bot.newHTTPContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/slack/commands", nil))
+	request := httpCtx.Context()
+	cancel := httpCtx.detach()
+	defer cancel()
+	httpCtx.done()
+	if request.Err() == nil {
+		t.Errorf("request context should be cancelled when the request is done")
+	}
+	if httpCtx.Context().Err() != nil {
+		t.Errorf("detached context should outlive the request")
+	}
+
+	bot.Shutdown(context.Background())
+	for _, ctx := range []*Context{httpCtx, socketCtx} {
+		select {
+		case <-ctx.Context().Done():
+		case <-time.After(time.Second):
+			t.Errorf("contexts should be cancelled on shutdown")
+		}
+	}
+}
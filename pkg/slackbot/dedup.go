@@ -0,0 +1,159 @@
+package slackbot
+
+import (
+	"github.com/humsie/log"
+	"github.com/slack-go/slack/slackevents"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEventTTL     = 10 * time.Minute
+	defaultEventMaxSize = 10000
+)
+
+// EventStore remembers the event_id of the Events API events that were
+// dispatched, so the retries Slack sends when the bot is slow to answer are
+// not handled twice. Implement it on a shared store such as Redis when
+// several instances of the bot receive events.
+type EventStore interface {
+	// MarkSeen records eventID and reports whether it was recorded before. It
+	// is called concurrently, so checking and recording must be atomic.
+	MarkSeen(eventID string) (seen bool, err error)
+	// Forget removes eventID, so the event is dispatched when Slack delivers
+	// it again. It is called for events that were seen but not handled.
+	Forget(eventID string) error
+}
+
+// MemoryEventStore is an in-memory EventStore that forgets event ids after a
+// TTL and holds at most a fixed number of them, evicting the oldest first. It
+// is the store a SlackBot uses by default.
+type MemoryEventStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	seen    map[string]time.Time
+	order   []string
+}
+
+// NewMemoryEventStore returns a MemoryEventStore that keeps event ids for ttl,
+// up to maxSize of them.
+func NewMemoryEventStore(ttl time.Duration, maxSize int) *MemoryEventStore {
+	return &MemoryEventStore{
+		ttl:     ttl,
+		maxSize: maxSize,
+		seen:    make(map[string]time.Time),
+	}
+}
+
+func (m *MemoryEventStore) MarkSeen(eventID string) (bool, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.expire(now)
+
+	if _, ok := m.seen[eventID]; ok {
+		return true, nil
+	}
+
+	m.seen[eventID] = now.Add(m.ttl)
+	m.order = append(m.order, eventID)
+
+	for m.maxSize > 0 && len(m.order) > m.maxSize {
+		delete(m.seen, m.order[0])
+		m.order = m.order[1:]
+	}
+
+	return false, nil
+
+}
+
+func (m *MemoryEventStore) Forget(eventID string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.seen[eventID]; !ok {
+		return nil
+	}
+
+	delete(m.seen, eventID)
+	m.order = slices.DeleteFunc(m.order, func(id string) bool {
+		return id == eventID
+	})
+
+	return nil
+
+}
+
+// expire removes the ids whose TTL passed. All ids share the same TTL, so they
+// expire in the order they were added.
+func (m *MemoryEventStore) expire(now time.Time) {
+
+	for len(m.order) > 0 && !now.Before(m.seen[m.order[0]]) {
+		delete(m.seen, m.order[0])
+		m.order = m.order[1:]
+	}
+
+}
+
+// SetEventStore replaces the store used to deduplicate events by event_id.
+// Pass nil to dispatch every delivery, retries included.
+func (s *SlackBot) SetEventStore(store EventStore) {
+	s.eventStore = store
+}
+
+// SetAckRetries makes the bot acknowledge retried deliveries of events
+// without dispatching them at all, whether the original delivery was seen or
+// not.
+func (s *SlackBot) SetAckRetries(ackRetries bool) {
+	s.ackRetries = ackRetries
+}
+
+// skipEvent reports whether a delivery of an Events API event should only be
+// acknowledged: because it is a retry and retries are not dispatched, or
+// because its event_id was dispatched before.
+func (s *SlackBot) skipEvent(eventsAPIEvent slackevents.EventsAPIEvent, retryAttempt int, retryReason string) bool {
+
+	if retryAttempt > 0 {
+		log.Debugf("Event retry %d received: %s", retryAttempt, retryReason)
+		if s.ackRetries {
+			return true
+		}
+	}
+
+	callbackEvent, ok := eventsAPIEvent.Data.(*slackevents.EventsAPICallbackEvent)
+	if s.eventStore == nil || !ok || callbackEvent.EventID == "" {
+		return false
+	}
+
+	seen, err := s.eventStore.MarkSeen(callbackEvent.EventID)
+	if err != nil {
+		log.Errorf("Could not deduplicate event %s: %v", callbackEvent.EventID, err)
+		return false
+	}
+	if seen {
+		log.Debugf("Skipping duplicate event %s", callbackEvent.EventID)
+	}
+
+	return seen
+
+}
+
+// forgetEvent removes the event_id of an event that was not handled from the
+// event store.
+func (s *SlackBot) forgetEvent(eventsAPIEvent slackevents.EventsAPIEvent) {
+
+	callbackEvent, ok := eventsAPIEvent.Data.(*slackevents.EventsAPICallbackEvent)
+	if s.eventStore == nil || !ok || callbackEvent.EventID == "" {
+		return
+	}
+
+	if err := s.eventStore.Forget(callbackEvent.EventID); err != nil {
+		log.Errorf("Could not forget event %s: %v", callbackEvent.EventID, err)
+	}
+
+}
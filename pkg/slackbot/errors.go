@@ -0,0 +1,162 @@
+package slackbot
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// CommandFuncE is a CommandFunc that can fail. The error is rendered for the
+// user by the ErrorRenderer of the bot.
+type CommandFuncE func(command slack.SlashCommand, ctx *Context) (slack.Message, error)
+
+// InteractionCallbackFuncE is an InteractionCallbackFunc that can fail. The
+// error is rendered for the user by the ErrorRenderer of the bot.
+type InteractionCallbackFuncE func(callback slack.InteractionCallback, ctx *Context) (slack.Message, error)
+
+// CallbackEventFuncE is a CallbackEventFunc that can fail. Events have no
+// user to answer, so the error is only logged.
+type CallbackEventFuncE func(event slackevents.EventsAPIEvent, ctx *Context) error
+
+// UserError is an error meant for the user, like invalid input or missing
+// permissions. The default ErrorRenderer shows its message as is.
+type UserError struct {
+	Message string
+	Err     error
+}
+
+// UserErrorf returns a UserError with a formatted message.
+func UserErrorf(format string, args ...interface{}) error {
+	return &UserError{Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *UserError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorRenderer turns an error returned by a handler into the message the
+// user gets. errorID identifies the error in the log, so users can mention it
+// when they report a problem.
+type ErrorRenderer func(err error, errorID string, req *Request, ctx *Context) slack.Message
+
+// DefaultErrorRenderer shows the message of a UserError and hides the details
+// of other errors behind their error id.
+func DefaultErrorRenderer(err error, errorID string, req *Request, ctx *Context) slack.Message {
+
+	var userErr *UserError
+	if errors.As(err, &userErr) {
+		return ephemeralMessage(userErr.Message)
+	}
+
+	return ephemeralMessage(fmt.Sprintf("Sorry, something went wrong. Please mention error id `%s` when you report this.", errorID))
+
+}
+
+// SetErrorRenderer replaces DefaultErrorRenderer.
+func (s *SlackBot) SetErrorRenderer(renderer ErrorRenderer) {
+	s.errorRenderer = renderer
+}
+
+func (s *SlackBot) RegisterCommandE(command string, handler CommandFuncE) error {
+	return s.RegisterCommand(command, commandE(handler))
+}
+
+func (s *SlackBot) RegisterInteractionCallbackE(interactionType slack.InteractionType, callbackId string, handler InteractionCallbackFuncE) error {
+	return s.RegisterInteractionCallback(interactionType, callbackId, interactionE(handler))
+}
+
+func (s *SlackBot) RegisterCallbackEventE(event slackevents.EventsAPIType, handler CallbackEventFuncE) error {
+	return s.RegisterCallbackEvent(event, eventE(handler))
+}
+
+// HandleE is Handle for a handler that can fail.
+func (c *Command) HandleE(handler CommandFuncE) *Command {
+	return c.Handle(commandE(handler))
+}
+
+// SubE is Sub for a handler that can fail.
+func (c *Command) SubE(name string, handler CommandFuncE) *Command {
+	return c.Sub(name, commandE(handler))
+}
+
+func commandE(handler CommandFuncE) CommandFunc {
+	return func(command slack.SlashCommand, ctx *Context) slack.Message {
+		msg, err := handler(command, ctx)
+		if err != nil {
+			return ctx.bot.handleError(err, &Request{Kind: RequestCommand, Command: &command}, ctx)
+		}
+		return msg
+	}
+}
+
+func interactionE(handler InteractionCallbackFuncE) InteractionCallbackFunc {
+	return func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		msg, err := handler(callback, ctx)
+		if err != nil {
+			return ctx.bot.handleError(err, &Request{Kind: RequestInteraction, Interaction: &callback}, ctx)
+		}
+		return msg
+	}
+}
+
+func eventE(handler CallbackEventFuncE) CallbackEventFunc {
+	return func(event slackevents.EventsAPIEvent, ctx *Context) {
+		if err := handler(event, ctx); err != nil {
+			ctx.bot.handleError(err, &Request{Kind: RequestEvent, Event: &event}, ctx)
+		}
+	}
+}
+
+// handleError logs an error returned by a handler and returns the message
+// that answers the request. Slack does not show the response to most
+// interactions, so an interaction with a response_url gets the message posted
+// there instead.
+func (s *SlackBot) handleError(err error, req *Request, ctx *Context) slack.Message {
+
+	msg := s.renderError(err, req, ctx)
+
+	if req.Kind == RequestInteraction && ctx.responseURL != "" {
+		ctx.respondDetached(msg)
+		return slack.Message{}
+	}
+
+	return msg
+
+}
+
+// renderError logs err with a new error id and renders it for the user with
+// the ErrorRenderer of the bot. Events have no user, so they get an empty
+// message.
+func (s *SlackBot) renderError(err error, req *Request, ctx *Context) slack.Message {
+
+	errorID := uuid.NewString()
+
+	var userErr *UserError
+	if errors.As(err, &userErr) {
+		log.Debugf("Handler for %s returned a user error [%s]: %v", req.Name(), errorID, err)
+	} else {
+		log.Errorf("Handler for %s failed [%s]: %v", req.Name(), errorID, err)
+	}
+
+	if req.Kind == RequestEvent {
+		return slack.Message{}
+	}
+
+	renderer := DefaultErrorRenderer
+	if s != nil && s.errorRenderer != nil {
+		renderer = s.errorRenderer
+	}
+
+	return renderer(err, errorID, req, ctx)
+
+}
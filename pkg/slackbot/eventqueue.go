@@ -0,0 +1,167 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack/slackevents"
+	"sync"
+	"time"
+)
+
+// AsyncEventOptions configures asynchronous event dispatch, see
+// SetAsyncEvents.
+type AsyncEventOptions struct {
+	// Workers is the number of events handled at the same time. Defaults to 1.
+	Workers int
+	// QueueSize is the number of acknowledged events that can wait for a
+	// worker. When the queue is full, new events are not acknowledged, so
+	// Slack delivers them again later.
+	QueueSize int
+	// HandlerTimeout is how long a worker waits for each handler of an event
+	// before it cancels the handler's ctx.Context() and moves on. A handler
+	// that ignores the context keeps running next to the workers, so Workers
+	// does not bound it, but Shutdown still waits for it. A zero timeout
+	// waits for the handlers to finish.
+	HandlerTimeout time.Duration
+}
+
+type queuedEvent struct {
+	event  slackevents.EventsAPIEvent
+	ctx    *Context
+	cancel context.CancelFunc
+}
+
+type eventQueue struct {
+	mu             sync.RWMutex
+	closed         bool
+	events         chan queuedEvent
+	handlerTimeout time.Duration
+	// wg tracks the workers and the handlers they stopped waiting for.
+	wg sync.WaitGroup
+}
+
+// SetAsyncEvents makes the bot acknowledge Events API events right away, over
+// HTTP and in socket mode, and handle them on a pool of workers. The context
+// the handlers get is already finished, so they cannot answer the delivery;
+// use the Web API instead. Call Shutdown to stop the workers.
+func (s *SlackBot) SetAsyncEvents(options AsyncEventOptions) error {
+
+	if s.eventQueue != nil {
+		return fmt.Errorf("async events already enabled")
+	}
+
+	if options.Workers < 1 {
+		options.Workers = 1
+	}
+	if options.QueueSize < 0 {
+		options.QueueSize = 0
+	}
+
+	queue := &eventQueue{events: make(chan queuedEvent, options.QueueSize), handlerTimeout: options.HandlerTimeout}
+
+	for i := 0; i < options.Workers; i++ {
+		queue.wg.Go(func() {
+			for queued := range queue.events {
+				s.runQueuedEvent(queued)
+			}
+		})
+	}
+
+	s.eventQueue = queue
+
+	return nil
+
+}
+
+// Shutdown stops accepting events and waits until the queued events are
+// handled, including handlers that outlived their HandlerTimeout, or ctx is
+// done. Events that arrive afterwards are not acknowledged,
+// so Slack delivers them again, e.g. to another instance. Finally the
+// ctx.Context() of all handlers that still run is cancelled.
+func (s *SlackBot) Shutdown(ctx context.Context) error {
+
+	defer s.cancelBase()
+
+	queue := s.eventQueue
+	if queue == nil {
+		return nil
+	}
+
+	queue.mu.Lock()
+	if !queue.closed {
+		queue.closed = true
+		close(queue.events)
+	}
+	queue.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		queue.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event queue not drained: %w", ctx.Err())
+	}
+
+}
+
+// push queues the event without blocking and reports whether there was room.
+func (q *eventQueue) push(queued queuedEvent) bool {
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	select {
+	case q.events <- queued:
+		return true
+	default:
+		return false
+	}
+
+}
+
+// dispatchEvent fires a callback event, or queues it when async events are
+// enabled. It returns false when the event could not be queued; the delivery
+// should then not be acknowledged.
+func (s *SlackBot) dispatchEvent(eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) bool {
+
+	if s.eventQueue == nil {
+		s.FireCallbackEvent(eventsAPIEvent, ctx)
+		return true
+	}
+
+	// The delivery is acknowledged by the caller, so the queued handlers get
+	// a finished copy of the context without the response writer.
+	detached := *ctx
+	detached.HTTPResponseWriter = nil
+	detached.Finish()
+	detached.queue = s.eventQueue
+	cancel := detached.detach()
+
+	if !s.eventQueue.push(queuedEvent{event: eventsAPIEvent, ctx: &detached, cancel: cancel}) {
+		log.Errorf("Event queue full, not acknowledging event %s", eventsAPIEvent.InnerEvent.Type)
+		cancel()
+		s.forgetEvent(eventsAPIEvent)
+		return false
+	}
+
+	return true
+
+}
+
+func (s *SlackBot) runQueuedEvent(queued queuedEvent) {
+
+	defer queued.cancel()
+
+	s.FireCallbackEvent(queued.event, queued.ctx)
+
+}
@@ -0,0 +1,88 @@
+package slackbot
+
+import (
+	"context"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack/slackevents"
+	"sync"
+)
+
+// EventDispatch selects how the handlers registered for the same event type
+// are run.
+type EventDispatch int
+
+const (
+	// EventDispatchSequential runs the handlers one after another, in
+	// registration order.
+	EventDispatchSequential EventDispatch = iota
+	// EventDispatchConcurrent runs the handlers in parallel and waits for all
+	// of them to finish. Each handler gets its own copy of the Context, so
+	// the fields it sets are not seen by the others.
+	EventDispatchConcurrent
+)
+
+func (s *SlackBot) SetEventDispatch(dispatch EventDispatch) {
+	s.eventDispatch = dispatch
+}
+
+func (s *SlackBot) runEventHandlers(eventFuncs []CallbackEventFunc, eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) {
+
+	if s.eventDispatch != EventDispatchConcurrent || len(eventFuncs) == 1 {
+		for _, eventFunc := range eventFuncs {
+			s.runEventHandler(eventFunc, eventsAPIEvent, ctx)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, eventFunc := range eventFuncs {
+		handlerCtx := *ctx
+		wg.Go(func() {
+			s.runEventHandler(eventFunc, eventsAPIEvent, &handlerCtx)
+		})
+	}
+	wg.Wait()
+
+}
+
+// runEventHandler runs a single handler and recovers from a panic in it, so
+// the other handlers of the event still run. A queued event with a
+// HandlerTimeout gets its own copy of the Context, whose ctx.Context() is
+// cancelled when the handler does not finish in time.
+func (s *SlackBot) runEventHandler(eventFunc CallbackEventFunc, eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) {
+
+	if ctx.queue == nil || ctx.queue.handlerTimeout <= 0 {
+		s.callEventHandler(eventFunc, eventsAPIEvent, ctx)
+		return
+	}
+
+	handlerCtx := *ctx
+	timeoutCtx, cancel := context.WithTimeout(ctx.Context(), ctx.queue.handlerTimeout)
+	handlerCtx.ctx = timeoutCtx
+
+	done := make(chan struct{})
+	ctx.queue.wg.Go(func() {
+		defer cancel()
+		defer close(done)
+		s.callEventHandler(eventFunc, eventsAPIEvent, &handlerCtx)
+	})
+
+	select {
+	case <-done:
+	case <-timeoutCtx.Done():
+		log.Errorf("Handler for event %s did not finish within %s", eventsAPIEvent.InnerEvent.Type, ctx.queue.handlerTimeout)
+	}
+
+}
+
+func (s *SlackBot) callEventHandler(eventFunc CallbackEventFunc, eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.reportPanic(r, &Request{Kind: RequestEvent, Event: &eventsAPIEvent}, ctx)
+		}
+	}()
+
+	eventFunc(eventsAPIEvent, ctx)
+
+}
@@ -0,0 +1,261 @@
+package slackbot
+
+import (
+	"context"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func appMention() slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type:       slackevents.CallbackEvent,
+		InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.AppMention), Data: &slackevents.AppMentionEvent{}},
+	}
+}
+
+func TestCallbackEventFanOut(t *testing.T) {
+	for _, dispatch := range []EventDispatch{EventDispatchSequential, EventDispatchConcurrent} {
+		bot := NewSlackBot("", "", "")
+		bot.SetEventDispatch(dispatch)
+
+		var mu sync.Mutex
+		called := make([]string, 0)
+		record := func(name string) CallbackEventFunc {
+			return func(event slackevents.EventsAPIEvent, ctx *Context) {
+				mu.Lock()
+				defer mu.Unlock()
+				called = append(called, name)
+			}
+		}
+
+		bot.RegisterCallbackEvent(slackevents.AppMention, record("first"))
+		bot.RegisterCallbackEvent(slackevents.AppMention, func(event slackevents.EventsAPIEvent, ctx *Context) {
+			panic("broken handler")
+		})
+		bot.RegisterCallbackEvent(slackevents.AppMention, record("second"))
+
+		bot.FireCallbackEvent(appMention(), &Context{})
+
+		if dispatch == EventDispatchSequential && !reflect.DeepEqual(called, []string{"first", "second"}) {
+			t.Errorf("sequential: handlers should run in registration order, got %v", called)
+		}
+		if len(called) != 2 {
+			t.Errorf("dispatch %d: a failing handler should not stop the others, got %v", dispatch, called)
+		}
+	}
+}
+
+func TestConcurrentEventContexts(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.SetEventDispatch(EventDispatchConcurrent)
+
+	var leaked atomic.Int32
+	for _, name := range []string{"first", "second"} {
+		bot.RegisterCallbackEvent(slackevents.AppMention, func(event slackevents.EventsAPIEvent, ctx *Context) {
+			ctx.Matches = []string{name}
+			ctx.SetValue(userKey{}, name)
+			time.Sleep(10 * time.Millisecond)
+			if ctx.Matches[0] != name || ctx.Value(userKey{}) != name {
+				leaked.Add(1)
+			}
+		})
+	}
+
+	bot.FireCallbackEvent(appMention(), &Context{})
+
+	if leaked.Load() != 0 {
+		t.Errorf("concurrent handlers should not share their Context")
+	}
+}
+
+func TestMessageFilter(t *testing.T) {
+	plain := &slackevents.MessageEvent{User: "U1", ChannelType: "im", TimeStamp: "1.1"}
+	reply := &slackevents.MessageEvent{User: "U1", ChannelType: "channel", TimeStamp: "1.2", ThreadTimeStamp: "1.1"}
+	bot := &slackevents.MessageEvent{BotID: "B1", SubType: "bot_message", ChannelType: "channel", TimeStamp: "1.3"}
+	edit := &slackevents.MessageEvent{SubType: "message_changed", ChannelType: "im", Message: &slack.Msg{User: "U1", Timestamp: "1.4", ThreadTimestamp: "1.1"}}
+
+	tests := []struct {
+		name   string
+		filter MessageFilter
+		want   []*slackevents.MessageEvent
+	}{
+		{"everything", MessageFilter{}, []*slackevents.MessageEvent{plain, reply, bot, edit}},
+		{"empty lists", MessageFilter{SubTypes: []string{}, ChannelTypes: []string{}}, []*slackevents.MessageEvent{plain, reply, bot, edit}},
+		{"plain messages", MessageFilter{SubTypes: []string{""}}, []*slackevents.MessageEvent{plain, reply}},
+		{"direct messages", MessageFilter{ChannelTypes: []string{"im", "mpim"}}, []*slackevents.MessageEvent{plain, edit}},
+		{"top level", MessageFilter{Thread: TopLevelOnly}, []*slackevents.MessageEvent{plain, bot}},
+		{"thread replies", MessageFilter{Thread: ThreadRepliesOnly}, []*slackevents.MessageEvent{reply, edit}},
+		{"humans", MessageFilter{Author: HumanAuthor}, []*slackevents.MessageEvent{plain, reply, edit}},
+		{"bots", MessageFilter{Author: BotAuthor}, []*slackevents.MessageEvent{bot}},
+	}
+
+	for _, tt := range tests {
+		got := make([]*slackevents.MessageEvent, 0)
+		for _, ev := range []*slackevents.MessageEvent{plain, reply, bot, edit} {
+			if tt.filter.Matches(ev) {
+				got = append(got, ev)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: matched %d messages, want %d", tt.name, len(got), len(tt.want))
+		}
+	}
+}
+
+func TestRegisterMessageEvent(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	count := 0
+	bot.RegisterMessageEvent(MessageFilter{SubTypes: []string{""}, Author: HumanAuthor}, func(event slackevents.EventsAPIEvent, ctx *Context) {
+		count++
+	})
+
+	bot.FireCallbackEvent(messageEvent(&slackevents.MessageEvent{User: "U1", Text: "hi"}), &Context{})
+	bot.FireCallbackEvent(messageEvent(&slackevents.MessageEvent{SubType: "channel_join", User: "U1"}), &Context{})
+	bot.FireCallbackEvent(messageEvent(&slackevents.MessageEvent{SubType: "bot_message", BotID: "B1"}), &Context{})
+
+	if count != 1 {
+		t.Errorf("handler should only get the plain human message, got %d calls", count)
+	}
+}
+
+func TestMemoryEventStore(t *testing.T) {
+	store := NewMemoryEventStore(time.Hour, 2)
+
+	for _, step := range []struct {
+		id   string
+		seen bool
+	}{
+		{"Ev1", false},
+		{"Ev1", true},
+		{"Ev2", false},
+		{"Ev3", false},
+		{"Ev1", false}, // evicted by Ev3
+		{"Ev3", true},
+	} {
+		if seen, _ := store.MarkSeen(step.id); seen != step.seen {
+			t.Errorf("%s: seen is %v, want %v", step.id, seen, step.seen)
+		}
+	}
+
+	store.Forget("Ev3")
+	if seen, _ := store.MarkSeen("Ev3"); seen {
+		t.Errorf("Ev3 should be forgotten")
+	}
+
+	store = NewMemoryEventStore(time.Millisecond, 10)
+	store.MarkSeen("Ev1")
+	time.Sleep(5 * time.Millisecond)
+	if seen, _ := store.MarkSeen("Ev1"); seen {
+		t.Errorf("Ev1 should have expired")
+	}
+}
+
+func TestSkipEvent(t *testing.T) {
+	event := appMention()
+	event.Data = &slackevents.EventsAPICallbackEvent{EventID: "Ev1"}
+
+	bot := NewSlackBot("", "", "")
+	if bot.skipEvent(event, 0, "") {
+		t.Errorf("first delivery should be dispatched")
+	}
+	if !bot.skipEvent(event, 1, "http_timeout") {
+		t.Errorf("retry of a dispatched event should be skipped")
+	}
+
+	bot = NewSlackBot("", "", "")
+	bot.SetAckRetries(true)
+	if !bot.skipEvent(event, 1, "http_timeout") {
+		t.Errorf("retries should be skipped with SetAckRetries")
+	}
+
+	bot = NewSlackBot("", "", "")
+	bot.SetEventStore(nil)
+	if bot.skipEvent(event, 0, "") || bot.skipEvent(event, 1, "http_timeout") {
+		t.Errorf("events should not be deduplicated without a store")
+	}
+}
+
+func TestAsyncEvents(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.SetAsyncEvents(AsyncEventOptions{Workers: 1, QueueSize: 1})
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var handled atomic.Int32
+	bot.RegisterCallbackEvent(slackevents.AppMention, func(event slackevents.EventsAPIEvent, ctx *Context) {
+		if !ctx.IsFinished() {
+			t.Errorf("queued events should get a finished context")
+		}
+		started <- struct{}{}
+		<-release
+		handled.Add(1)
+	})
+
+	if !bot.dispatchEvent(appMention(), &Context{}) {
+		t.Fatalf("first event should be queued")
+	}
+	<-started
+	if !bot.dispatchEvent(appMention(), &Context{}) {
+		t.Fatalf("second event should wait in the queue")
+	}
+	if bot.dispatchEvent(appMention(), &Context{}) {
+		t.Errorf("third event should be refused by the full queue")
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bot.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if handled.Load() != 2 {
+		t.Errorf("%d events handled, want 2", handled.Load())
+	}
+	if bot.dispatchEvent(appMention(), &Context{}) {
+		t.Errorf("events after Shutdown should be refused")
+	}
+}
+
+func TestAsyncEventHandlerTimeout(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.SetAsyncEvents(AsyncEventOptions{Workers: 1, QueueSize: 1, HandlerTimeout: 50 * time.Millisecond})
+
+	// Each handler gets the full timeout, not a share of the event's.
+	var timedOut atomic.Int32
+	for i := 0; i < 2; i++ {
+		bot.RegisterCallbackEvent(slackevents.AppMention, func(event slackevents.EventsAPIEvent, ctx *Context) {
+			select {
+			case <-ctx.Context().Done():
+				timedOut.Add(1)
+			case <-time.After(30 * time.Millisecond):
+			}
+		})
+	}
+	release := make(chan struct{})
+	bot.RegisterCallbackEvent(slackevents.AppMention, func(event slackevents.EventsAPIEvent, ctx *Context) {
+		<-release
+	})
+
+	bot.dispatchEvent(appMention(), &Context{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := bot.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown should wait for the handler that ignores its timeout")
+	}
+	if timedOut.Load() != 0 {
+		t.Errorf("%d handlers timed out within their own timeout", timedOut.Load())
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bot.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown after the handler returned: %v", err)
+	}
+}
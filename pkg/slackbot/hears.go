@@ -0,0 +1,232 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack/slackevents"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HearsScope selects where Hears listens. Combine scopes with |.
+type HearsScope int
+
+const (
+	// HearDirectMention matches messages that mention the bot, delivered as
+	// app_mention events. The leading mention is stripped from the text.
+	HearDirectMention HearsScope = 1 << iota
+	// HearDirectMessage matches messages in a direct message with the bot.
+	HearDirectMessage
+	// HearAmbient matches channel messages that do not mention the bot.
+	HearAmbient
+
+	HearAll = HearDirectMention | HearDirectMessage | HearAmbient
+)
+
+// HeardMessage is a message matched by Hears.
+type HeardMessage struct {
+	Scope           HearsScope
+	Channel         string
+	ChannelType     string
+	User            string
+	Text            string
+	TimeStamp       string
+	ThreadTimeStamp string
+}
+
+type HearsFunc func(message HeardMessage, ctx *Context)
+
+type hears struct {
+	pattern *regexp.Regexp
+	scope   HearsScope
+	handler HearsFunc
+}
+
+const (
+	// identityTimeout bounds a lookup of the bot identity.
+	identityTimeout = 5 * time.Second
+	// identityBackoff is how long a failed lookup is not retried.
+	identityBackoff = time.Minute
+)
+
+type botIdentity struct {
+	mu        sync.Mutex
+	userID    string
+	botID     string
+	lookingUp bool
+	retryAt   time.Time
+}
+
+// Hears registers handler for messages whose text matches pattern, in the
+// given scopes (all scopes when none are given). The submatches of pattern
+// are available as ctx.Matches. Every matching handler runs, in registration
+// order. Messages of the bot itself, edits, deletions and other non-message
+// subtypes are skipped; until the bot's identity is looked up, so are the
+// messages of other bots.
+//
+// Hears listens to message and app_mention events, so the app needs to be
+// subscribed to those events.
+func (s *SlackBot) Hears(pattern *regexp.Regexp, handler HearsFunc, scopes ...HearsScope) error {
+
+	if pattern == nil || handler == nil {
+		return fmt.Errorf("hears needs a pattern and a handler")
+	}
+
+	scope := HearsScope(0)
+	for _, sc := range scopes {
+		scope |= sc
+	}
+	if scope == 0 {
+		scope = HearAll
+	}
+
+	if len(s.registeredHears) == 0 {
+		if err := s.RegisterCallbackEvent(slackevents.Message, s.hearMessage); err != nil {
+			return err
+		}
+		if err := s.RegisterCallbackEvent(slackevents.AppMention, s.hearMention); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Registering hears: %s", pattern)
+	s.registeredHears = append(s.registeredHears, hears{pattern: pattern, scope: scope, handler: handler})
+
+	return nil
+
+}
+
+// identity returns the user and bot id of the bot, or empty ids while they
+// are unknown. Only a successful lookup is kept. One lookup runs at a time,
+// without holding the lock, and a failed one is retried after
+// identityBackoff.
+func (s *SlackBot) identity(ctx *Context) (userID, botID string) {
+
+	s.botIdentity.mu.Lock()
+	if s.botIdentity.userID != "" || s.api == nil || s.botIdentity.lookingUp || time.Now().Before(s.botIdentity.retryAt) {
+		defer s.botIdentity.mu.Unlock()
+		return s.botIdentity.userID, s.botIdentity.botID
+	}
+	s.botIdentity.lookingUp = true
+	s.botIdentity.mu.Unlock()
+
+	lookupCtx, cancel := context.WithTimeout(ctx.Context(), identityTimeout)
+	defer cancel()
+	auth, err := s.api.AuthTestContext(lookupCtx)
+
+	s.botIdentity.mu.Lock()
+	defer s.botIdentity.mu.Unlock()
+
+	s.botIdentity.lookingUp = false
+	if err != nil {
+		log.Errorf("Could not look up the bot identity: %v", err)
+		s.botIdentity.retryAt = time.Now().Add(identityBackoff)
+	} else {
+		s.botIdentity.userID = auth.UserID
+		s.botIdentity.botID = auth.BotID
+	}
+
+	return s.botIdentity.userID, s.botIdentity.botID
+
+}
+
+// isOwnMessage reports whether a message was sent by the bot with the given
+// identity. While the identity is unknown every bot message may be the bot's
+// own, so they are all treated as such to avoid answering itself.
+func isOwnMessage(ownUserID, ownBotID, user, botID string) bool {
+
+	if ownUserID == "" {
+		return botID != ""
+	}
+
+	return user == ownUserID || (ownBotID != "" && botID == ownBotID)
+
+}
+
+func (s *SlackBot) hearMessage(event slackevents.EventsAPIEvent, ctx *Context) {
+
+	ev, ok := event.InnerEvent.Data.(*slackevents.MessageEvent)
+	if !ok {
+		return
+	}
+
+	switch ev.SubType {
+	case "", "bot_message", "file_share", "thread_broadcast":
+	default:
+		return
+	}
+
+	ownUserID, ownBotID := s.identity(ctx)
+	if isOwnMessage(ownUserID, ownBotID, ev.User, ev.BotID) {
+		return
+	}
+
+	scope := HearAmbient
+	if ev.ChannelType == "im" {
+		scope = HearDirectMessage
+	} else if ownUserID != "" && strings.Contains(ev.Text, "<@"+ownUserID+">") {
+		// Handled by the app_mention event of the same message.
+		return
+	}
+
+	s.fireHears(HeardMessage{
+		Scope:           scope,
+		Channel:         ev.Channel,
+		ChannelType:     ev.ChannelType,
+		User:            ev.User,
+		Text:            ev.Text,
+		TimeStamp:       ev.TimeStamp,
+		ThreadTimeStamp: ev.ThreadTimeStamp,
+	}, ctx)
+
+}
+
+func (s *SlackBot) hearMention(event slackevents.EventsAPIEvent, ctx *Context) {
+
+	ev, ok := event.InnerEvent.Data.(*slackevents.AppMentionEvent)
+	if !ok {
+		return
+	}
+
+	ownUserID, ownBotID := s.identity(ctx)
+	if isOwnMessage(ownUserID, ownBotID, ev.User, ev.BotID) {
+		return
+	}
+
+	text := ev.Text
+	if ownUserID != "" {
+		text = strings.TrimPrefix(strings.TrimSpace(text), "<@"+ownUserID+">")
+	}
+
+	s.fireHears(HeardMessage{
+		Scope:           HearDirectMention,
+		Channel:         ev.Channel,
+		User:            ev.User,
+		Text:            strings.TrimSpace(text),
+		TimeStamp:       ev.TimeStamp,
+		ThreadTimeStamp: ev.ThreadTimeStamp,
+	}, ctx)
+
+}
+
+func (s *SlackBot) fireHears(message HeardMessage, ctx *Context) {
+
+	for _, h := range s.registeredHears {
+		if h.scope&message.Scope == 0 {
+			continue
+		}
+
+		matches := h.pattern.FindStringSubmatch(message.Text)
+		if matches == nil {
+			continue
+		}
+
+		log.Debugf("Heard %s", h.pattern)
+		ctx.Matches = matches
+		h.handler(message, ctx)
+	}
+
+}
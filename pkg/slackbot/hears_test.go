@@ -0,0 +1,111 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func messageEvent(ev *slackevents.MessageEvent) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type:       slackevents.CallbackEvent,
+		InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.Message), Data: ev},
+	}
+}
+
+func TestHears(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.botIdentity.userID, bot.botIdentity.botID = "UBOT", "BBOT"
+
+	heard := make([]string, 0)
+	bot.Hears(regexp.MustCompile(`deploy (\S+) to (\w+)`), func(message HeardMessage, ctx *Context) {
+		heard = append(heard, ctx.Matches[1]+"@"+ctx.Matches[2])
+	}, HearDirectMention, HearDirectMessage)
+	bot.Hears(regexp.MustCompile(`[A-Z]+-[0-9]+`), func(message HeardMessage, ctx *Context) {
+		heard = append(heard, ctx.Matches[0])
+	})
+
+	events := []slackevents.EventsAPIEvent{
+		// ambient: only the ticket pattern listens
+		messageEvent(&slackevents.MessageEvent{User: "U1", ChannelType: "channel", Text: "deploy api to prod for OPS-1"}),
+		// direct message
+		messageEvent(&slackevents.MessageEvent{User: "U1", ChannelType: "im", Text: "deploy api to prod"}),
+		// mention as message event is left to the app_mention event
+		messageEvent(&slackevents.MessageEvent{User: "U1", ChannelType: "channel", Text: "<@UBOT> deploy web to dev"}),
+		{
+			Type:       slackevents.CallbackEvent,
+			InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.AppMention), Data: &slackevents.AppMentionEvent{User: "U1", Text: "<@UBOT> deploy web to dev"}},
+		},
+		// skipped: own messages, edits and joins
+		messageEvent(&slackevents.MessageEvent{User: "UBOT", ChannelType: "im", Text: "OPS-2"}),
+		messageEvent(&slackevents.MessageEvent{BotID: "BBOT", SubType: "bot_message", Text: "OPS-3"}),
+		messageEvent(&slackevents.MessageEvent{User: "U1", SubType: "message_changed", Text: "OPS-4"}),
+		messageEvent(&slackevents.MessageEvent{User: "U1", SubType: "channel_join", Text: "OPS-5"}),
+		// other bots are heard
+		messageEvent(&slackevents.MessageEvent{BotID: "BOTHER", SubType: "bot_message", Text: "OPS-6"}),
+	}
+	for _, event := range events {
+		bot.FireCallbackEvent(event, &Context{})
+	}
+
+	want := []string{"OPS-1", "api@prod", "web@dev", "OPS-6"}
+	if len(heard) != len(want) {
+		t.Fatalf("got %v, want %v", heard, want)
+	}
+	for i := range want {
+		if heard[i] != want[i] {
+			t.Errorf("got %v, want %v", heard, want)
+			break
+		}
+	}
+}
+
+func TestHearsIdentityRetry(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if lookups.Add(1) == 1 {
+			w.Write([]byte(`{"ok":false,"error":"ratelimited"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"user_id":"UBOT","bot_id":"BBOT"}`))
+	}))
+	defer server.Close()
+
+	bot := NewSlackBot("", "", "")
+	bot.api = slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))
+
+	heard := make([]string, 0)
+	bot.Hears(regexp.MustCompile(`OPS-[0-9]+`), func(message HeardMessage, ctx *Context) {
+		heard = append(heard, ctx.Matches[0])
+	})
+
+	fire := func(ev *slackevents.MessageEvent) {
+		bot.FireCallbackEvent(messageEvent(ev), &Context{})
+	}
+
+	// Skipped while the identity is unknown: they may be the bot's own.
+	fire(&slackevents.MessageEvent{BotID: "BOTHER", SubType: "bot_message", Text: "OPS-1"})
+	fire(&slackevents.MessageEvent{BotID: "BOTHER", SubType: "bot_message", Text: "OPS-2"})
+	if lookups.Load() != 1 {
+		t.Errorf("a failed lookup should not be retried right away, got %d lookups", lookups.Load())
+	}
+
+	// The backoff has passed.
+	bot.botIdentity.retryAt = time.Time{}
+	fire(&slackevents.MessageEvent{BotID: "BBOT", SubType: "bot_message", Text: "OPS-3"})
+	fire(&slackevents.MessageEvent{BotID: "BOTHER", SubType: "bot_message", Text: "OPS-4"})
+	fire(&slackevents.MessageEvent{User: "U1", Text: "OPS-5"})
+
+	if len(heard) != 2 || heard[0] != "OPS-4" || heard[1] != "OPS-5" {
+		t.Errorf("got %v, want [OPS-4 OPS-5]", heard)
+	}
+	if lookups.Load() != 2 {
+		t.Errorf("a successful lookup should be kept, got %d lookups", lookups.Load())
+	}
+}
@@ -0,0 +1,141 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/slack-go/slack"
+	"sort"
+	"strings"
+)
+
+// Describe sets the description shown in the generated help.
+func (c *Command) Describe(description string) *Command {
+	c.description = description
+	return c
+}
+
+// Usage overrides the usage line generated from the subcommands and params.
+func (c *Command) Usage(usage string) *Command {
+	c.usageText = usage
+	return c
+}
+
+// Example adds example invocations to the generated help.
+func (c *Command) Example(examples ...string) *Command {
+	c.examples = append(c.examples, examples...)
+	return c
+}
+
+func (c *Command) hasHelp() bool {
+	return c.description != "" || c.usageText != "" || len(c.examples) > 0 || len(c.subOrder) > 0 || c.hasParams()
+}
+
+func (c *Command) helpMessage() slack.Message {
+
+	title := fmt.Sprintf("*`%s`*", c.Path())
+	if c.description != "" {
+		title += "\n" + c.description
+	}
+
+	sections := []string{
+		title,
+		fmt.Sprintf("*Usage*\n`%s`", c.usageLine()),
+	}
+
+	if len(c.subOrder) > 0 {
+		lines := []string{"*Subcommands*"}
+		for _, name := range c.subOrder {
+			lines = append(lines, helpLine(c.subCommands[name].Path(), c.subCommands[name].description))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if len(c.args) > 0 {
+		lines := []string{"*Arguments*"}
+		for _, arg := range c.args {
+			lines = append(lines, helpLine(arg.placeholder(), arg.Description))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if len(c.flags) > 0 {
+		lines := []string{"*Flags*"}
+		for _, flag := range c.flags {
+			name := "--" + flag.Name
+			if flag.Type != ArgBool {
+				name += "=" + flag.placeholder()
+			}
+			lines = append(lines, helpLine(name, flag.Description))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if len(c.examples) > 0 {
+		lines := []string{"*Examples*"}
+		for _, example := range c.examples {
+			lines = append(lines, fmt.Sprintf("`%s`", example))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	return helpBlocks(fmt.Sprintf("Help for %s", c.Path()), sections)
+
+}
+
+// HelpMessage lists every registered command with its description.
+func (s *SlackBot) HelpMessage() slack.Message {
+
+	names := make([]string, 0, len(s.registeredCommands))
+	for name := range s.registeredCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"*Available commands*"}
+	for _, name := range names {
+		lines = append(lines, helpLine(name, s.registeredCommands[name].description))
+	}
+
+	return helpBlocks("Available commands", []string{
+		strings.Join(lines, "\n"),
+		"Use `/<command> help` for the details of a command.",
+	})
+
+}
+
+// RegisterHelpCommand registers a command, like /help, that answers with
+// HelpMessage.
+func (s *SlackBot) RegisterHelpCommand(command string) error {
+
+	err := s.RegisterCommand(command, func(slack.SlashCommand, *Context) slack.Message {
+		return s.HelpMessage()
+	})
+	if err != nil {
+		return err
+	}
+
+	s.registeredCommands[command].Describe("Show the available commands")
+
+	return nil
+
+}
+
+func helpLine(name, description string) string {
+	if description == "" {
+		return fmt.Sprintf("• `%s`", name)
+	}
+	return fmt.Sprintf("• `%s` – %s", name, description)
+}
+
+func helpBlocks(text string, sections []string) slack.Message {
+
+	blocks := make([]slack.Block, 0, len(sections))
+	for _, section := range sections {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, section, false, false), nil, nil))
+	}
+
+	msg := ephemeralMessage(text)
+	msg.Blocks = slack.Blocks{BlockSet: blocks}
+
+	return msg
+
+}
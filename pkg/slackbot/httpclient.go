@@ -9,13 +9,14 @@ import (
 	"github.com/slack-go/slack/slackevents"
 	"io"
 	"net/http"
+	"strconv"
 )
 
 func (s *SlackBot) SetHTTPHandleFunctions(http *http.ServeMux) {
 
 	http.HandleFunc("/events", s.DefaultHandler)
 	http.HandleFunc("/slack/events", s.EventsHandler)
-	http.HandleFunc("/slack/load-options", s.DefaultHandler)
+	http.HandleFunc("/slack/load-options", s.LoadOptionsHandler)
 
 	http.HandleFunc("/slack/actions", s.ActionsHandler)
 	http.HandleFunc("/slack/commands", s.CommandsHandler)
@@ -71,10 +72,11 @@ func (s *SlackBot) ActionsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := s.newHTTPContext(w, r)
-	response := s.FireInteractiveCallback(payload, ctx)
+	defer ctx.done()
+	response := s.fireInteraction(payload, ctx)
 
 	if !ctx.IsFinished() {
-		s.renderJSON(w, r, response)
+		ctx.acknowledge(response)
 	}
 
 }
@@ -95,6 +97,7 @@ func (s *SlackBot) CommandsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ctx := s.newHTTPContext(w, r)
+	defer ctx.done()
 	payload := s.FireSlashCommand(command, ctx)
 
 	if !ctx.IsFinished() {
@@ -137,8 +140,17 @@ func (s *SlackBot) EventsHandler(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/plain")
 		w.Write([]byte(res.Challenge))
 	case slackevents.CallbackEvent:
+		retryAttempt, _ := strconv.Atoi(r.Header.Get("X-Slack-Retry-Num"))
+		if s.skipEvent(eventsAPIEvent, retryAttempt, r.Header.Get("X-Slack-Retry-Reason")) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
 		ctx := s.newHTTPContext(w, r)
-		s.FireCallbackEvent(eventsAPIEvent, ctx)
+		defer ctx.done()
+		if !s.dispatchEvent(eventsAPIEvent, ctx) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	default:
 		log.Debugln("Unhandled event type: ", eventsAPIEvent.Type)
@@ -0,0 +1,115 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack/slackevents"
+	"slices"
+)
+
+type ThreadFilter int
+
+const (
+	AnyThread ThreadFilter = iota
+	// TopLevelOnly matches messages that are not a reply in a thread.
+	TopLevelOnly
+	// ThreadRepliesOnly matches replies in a thread, including the ones
+	// broadcast to the channel.
+	ThreadRepliesOnly
+)
+
+type AuthorFilter int
+
+const (
+	AnyAuthor AuthorFilter = iota
+	HumanAuthor
+	BotAuthor
+)
+
+// MessageFilter selects the message events a handler registered with
+// RegisterMessageEvent gets. Empty fields match every message.
+type MessageFilter struct {
+	// SubTypes lists the accepted message subtypes, like "message_changed"
+	// or "channel_join". Use "" for plain messages.
+	SubTypes []string
+	// ChannelTypes lists the accepted channel types: "im", "mpim",
+	// "channel" and "group".
+	ChannelTypes []string
+	Thread       ThreadFilter
+	Author       AuthorFilter
+}
+
+// Matches reports whether the message event passes the filter.
+func (f MessageFilter) Matches(ev *slackevents.MessageEvent) bool {
+
+	if len(f.SubTypes) > 0 && !slices.Contains(f.SubTypes, ev.SubType) {
+		return false
+	}
+
+	if len(f.ChannelTypes) > 0 && !slices.Contains(f.ChannelTypes, ev.ChannelType) {
+		return false
+	}
+
+	switch f.Thread {
+	case TopLevelOnly:
+		if isThreadReply(ev) {
+			return false
+		}
+	case ThreadRepliesOnly:
+		if !isThreadReply(ev) {
+			return false
+		}
+	}
+
+	switch f.Author {
+	case HumanAuthor:
+		if isBotMessage(ev) {
+			return false
+		}
+	case BotAuthor:
+		if !isBotMessage(ev) {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+// isThreadReply reports whether the message, or the edited message for a
+// message_changed event, is a reply in a thread.
+func isThreadReply(ev *slackevents.MessageEvent) bool {
+
+	threadTimeStamp, timeStamp := ev.ThreadTimeStamp, ev.TimeStamp
+	if ev.Message != nil && threadTimeStamp == "" {
+		threadTimeStamp, timeStamp = ev.Message.ThreadTimestamp, ev.Message.Timestamp
+	}
+
+	return threadTimeStamp != "" && threadTimeStamp != timeStamp
+
+}
+
+func isBotMessage(ev *slackevents.MessageEvent) bool {
+
+	if ev.BotID != "" || ev.SubType == "bot_message" {
+		return true
+	}
+
+	return ev.Message != nil && (ev.Message.BotID != "" || ev.Message.SubType == "bot_message")
+
+}
+
+// RegisterMessageEvent registers handler for the message events that pass
+// filter, so it does not have to sort out edits, bot messages, joins and
+// thread replies itself:
+//
+//	bot.RegisterMessageEvent(slackbot.MessageFilter{
+//		SubTypes:     []string{""},
+//		ChannelTypes: []string{"im"},
+//		Author:       slackbot.HumanAuthor,
+//	}, DirectMessage)
+func (s *SlackBot) RegisterMessageEvent(filter MessageFilter, handler CallbackEventFunc) error {
+	return s.RegisterCallbackEvent(slackevents.Message, func(event slackevents.EventsAPIEvent, ctx *Context) {
+		if ev, ok := event.InnerEvent.Data.(*slackevents.MessageEvent); ok && filter.Matches(ev) {
+			handler(event, ctx)
+		}
+	})
+}
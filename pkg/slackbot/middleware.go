@@ -0,0 +1,165 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"slices"
+)
+
+type RequestKind int
+
+const (
+	RequestCommand RequestKind = iota
+	RequestInteraction
+	RequestEvent
+)
+
+// Request is the slash command, interaction or event a Handler handles. Only
+// the field that matches Kind is set.
+type Request struct {
+	Kind        RequestKind
+	Command     *slack.SlashCommand
+	Interaction *slack.InteractionCallback
+	Event       *slackevents.EventsAPIEvent
+}
+
+// Name returns the slash command, the callback id (or action id of a block
+// action or options request) or the event type of the request, e.g. for
+// logging or metrics.
+func (r *Request) Name() string {
+
+	switch {
+	case r.Command != nil:
+		return r.Command.Command
+	case r.Interaction != nil && r.Interaction.CallbackID != "":
+		return r.Interaction.CallbackID
+	case r.Interaction != nil && r.Interaction.View.CallbackID != "":
+		return r.Interaction.View.CallbackID
+	case r.Interaction != nil && len(r.Interaction.ActionCallback.BlockActions) > 0:
+		return r.Interaction.ActionCallback.BlockActions[0].ActionID
+	case r.Interaction != nil && r.Interaction.ActionID != "":
+		// block_suggestion payloads carry the action_id at the top level.
+		return r.Interaction.ActionID
+	case r.Event != nil:
+		return r.Event.InnerEvent.Type
+	}
+
+	return ""
+
+}
+
+// Handler handles a request and returns the response: a slack.Message for
+// slash commands and most interactions, the response of the payload type for
+// e.g. view submissions and options loaders, and nil for events.
+type Handler func(req *Request, ctx *Context) interface{}
+
+// Middleware wraps a Handler. It can run code before and after calling next,
+// or return a response without calling next to short-circuit the request:
+//
+//	func RequireAdmin(next slackbot.Handler) slackbot.Handler {
+//		return func(req *slackbot.Request, ctx *slackbot.Context) interface{} {
+//			if req.Command != nil && !isAdmin(req.Command.UserID) {
+//				return slack.Message{Msg: slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: "Admins only"}}
+//			}
+//			return next(req, ctx)
+//		}
+//	}
+type Middleware func(next Handler) Handler
+
+// Use adds middleware that runs around every slash command, interaction and
+// event, over HTTP and in socket mode, in the order it was added.
+func (s *SlackBot) Use(middleware ...Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// Use adds middleware that runs around the handler of the command and of its
+// subcommands, inside the middleware added with SlackBot.Use. For an Async
+// command it runs in the goroutine of the handler.
+func (c *Command) Use(middleware ...Middleware) *Command {
+	c.middleware = append(c.middleware, middleware...)
+	return c
+}
+
+// commandMiddleware returns the middleware of c and its parents, outermost
+// first.
+func (c *Command) commandMiddleware() []Middleware {
+	if c.parent == nil {
+		return c.middleware
+	}
+	return slices.Concat(c.parent.commandMiddleware(), c.middleware)
+}
+
+// dispatchHandler wraps handler in the panic recovery and the middleware of
+// the bot.
+func (s *SlackBot) dispatchHandler(handler Handler) Handler {
+	return chain(handler, append([]Middleware{s.recoverPanics}, s.middleware...))
+}
+
+func chain(handler Handler, middleware []Middleware) Handler {
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+
+}
+
+// messageResponse returns the message of a Handler response, or an empty
+// message when the response is not one.
+func messageResponse(response interface{}) slack.Message {
+
+	switch message := response.(type) {
+	case slack.Message:
+		return message
+	case *slack.Message:
+		if message != nil {
+			return *message
+		}
+	}
+
+	return slack.Message{}
+
+}
+
+// WrapCommand returns handler wrapped in middleware, for a single command.
+func WrapCommand(handler CommandFunc, middleware ...Middleware) CommandFunc {
+
+	wrapped := chain(func(req *Request, ctx *Context) interface{} {
+		return handler(*req.Command, ctx)
+	}, middleware)
+
+	return func(command slack.SlashCommand, ctx *Context) slack.Message {
+		return messageResponse(wrapped(&Request{Kind: RequestCommand, Command: &command}, ctx))
+	}
+
+}
+
+// WrapInteraction returns handler wrapped in middleware, for a single
+// interaction callback.
+func WrapInteraction(handler InteractionCallbackFunc, middleware ...Middleware) InteractionCallbackFunc {
+
+	wrapped := chain(func(req *Request, ctx *Context) interface{} {
+		return handler(*req.Interaction, ctx)
+	}, middleware)
+
+	return func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		return messageResponse(wrapped(&Request{Kind: RequestInteraction, Interaction: &callback}, ctx))
+	}
+
+}
+
+// WrapEvent returns handler wrapped in middleware, for a single event
+// handler.
+func WrapEvent(handler CallbackEventFunc, middleware ...Middleware) CallbackEventFunc {
+
+	wrapped := chain(func(req *Request, ctx *Context) interface{} {
+		handler(*req.Event, ctx)
+		return nil
+	}, middleware)
+
+	return func(event slackevents.EventsAPIEvent, ctx *Context) {
+		wrapped(&Request{Kind: RequestEvent, Event: &event}, ctx)
+	}
+
+}
@@ -0,0 +1,142 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func trace(calls *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request, ctx *Context) interface{} {
+			*calls = append(*calls, name+":"+req.Name())
+			return next(req, ctx)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	calls := make([]string, 0)
+
+	bot.Use(trace(&calls, "first"), trace(&calls, "second"))
+	bot.Command("/deploy").Use(trace(&calls, "deploy")).Sub("status", reply("status"))
+	bot.Command("/deploy").SubCommand("status").Use(trace(&calls, "status"))
+	bot.RegisterCallbackEvent(slackevents.AppMention, WrapEvent(func(event slackevents.EventsAPIEvent, ctx *Context) {
+		calls = append(calls, "handler")
+	}, trace(&calls, "mention")))
+
+	msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/deploy", Text: "status"}, &Context{})
+	if msg.Text != "status:" {
+		t.Errorf("got %q, want the status handler", msg.Text)
+	}
+	bot.FireCallbackEvent(appMention(), &Context{})
+
+	want := []string{
+		"first:/deploy", "second:/deploy", "deploy:/deploy", "status:/deploy",
+		"first:app_mention", "second:app_mention", "mention:app_mention", "handler",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	deny := func(next Handler) Handler {
+		return func(req *Request, ctx *Context) interface{} {
+			if req.Kind == RequestInteraction {
+				return ephemeralMessage("denied")
+			}
+			return next(req, ctx)
+		}
+	}
+
+	called := false
+	bot.RegisterInteractionCallback(slack.InteractionTypeShortcut, "open", WrapInteraction(func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		called = true
+		return slack.Message{}
+	}, deny))
+	bot.Command("/admin").Use(deny).Handle(reply("admin"))
+
+	msg := bot.fireInteractiveCallback(slack.InteractionCallback{Type: slack.InteractionTypeShortcut, CallbackID: "open"}, &Context{})
+	if called || msg.Text != "denied" {
+		t.Errorf("interaction should be denied, got %q", msg.Text)
+	}
+
+	bot.Use(deny)
+	if response := bot.fireInteraction(blockActionsPayload(&slack.BlockAction{ActionID: "approve"}), &Context{}); messageResponse(response).Text != "denied" {
+		t.Errorf("bot middleware should deny every interaction, got %v", response)
+	}
+	if msg := bot.FireInteractiveCallback(blockActionsPayload(&slack.BlockAction{ActionID: "approve"}), &Context{}); msg.Text != "denied" {
+		t.Errorf("FireInteractiveCallback should run the bot middleware, got %q", msg.Text)
+	}
+	if msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/admin"}, &Context{}); msg.Text != "admin:" {
+		t.Errorf("commands should pass, got %q", msg.Text)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	server, received := webhookServer(t)
+
+	bot := NewSlackBot(testSigningSecret, "", "")
+	bot.SetPanicMessage("oops")
+	reported := make(chan string, 4)
+	bot.OnPanic(func(recovered interface{}, stack []byte, req *Request, ctx *Context) {
+		reported <- fmt.Sprintf("%s:%v", req.Name(), recovered)
+	})
+
+	broken := func(command slack.SlashCommand, ctx *Context) slack.Message {
+		panic("broken " + command.Command)
+	}
+	bot.RegisterCommand("/broken", broken)
+	bot.Command("/slow").Async("").Handle(broken)
+	bot.RegisterBlockAction("", "click", func(action slack.BlockAction, callback slack.InteractionCallback, ctx *Context) slack.Message {
+		panic("broken click")
+	})
+	bot.RegisterOptionsLoader("pick", func(suggestion slack.InteractionCallback, ctx *Context) OptionsResponse {
+		panic("broken pick")
+	})
+
+	ctx := &Context{bot: bot}
+	if msg := bot.FireSlashCommand(slack.SlashCommand{Command: "/broken"}, ctx); msg.Text != "oops" || msg.ResponseType != slack.ResponseTypeEphemeral {
+		t.Errorf("panicking command should answer the panic message, got %+v", msg.Msg)
+	}
+	if got := <-reported; got != "/broken:broken /broken" {
+		t.Errorf("reported %q", got)
+	}
+
+	w := httptest.NewRecorder()
+	bot.LoadOptionsHandler(w, signedRequest("/slack/load-options", suggestionForm("pick", "")))
+	if got := strings.TrimSpace(w.Body.String()); w.Code != http.StatusOK || got != `{"options":[]}` {
+		t.Errorf("panicking options loader should answer an empty list, got %d %s", w.Code, got)
+	}
+	if got := <-reported; got != "pick:broken pick" {
+		t.Errorf("reported %q", got)
+	}
+
+	payload := blockActionsPayload(&slack.BlockAction{ActionID: "click"})
+	payload.ResponseURL = server.URL
+	bot.FireInteractiveCallback(payload, &Context{bot: bot})
+	bot.FireSlashCommand(slack.SlashCommand{Command: "/slow", ResponseURL: server.URL}, &Context{bot: bot})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			if msg.Text != "oops" {
+				t.Errorf("got follow-up %q, want the panic message", msg.Text)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no panic message posted")
+		}
+	}
+	if len(reported) != 2 {
+		t.Errorf("%d more panics reported, want 2", len(reported))
+	}
+}
@@ -0,0 +1,68 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// SetCommandNotFound sets the handler for slash commands that are not
+// registered, e.g. to show guidance or forward them to another system. By
+// default the reply is "Unknown command: /cmd text".
+func (s *SlackBot) SetCommandNotFound(handler CommandFunc) {
+	s.commandNotFound = handler
+}
+
+// SetInteractionNotFound sets the handler for interactions without a
+// registered callback, both of unknown payload types and of known types with
+// an unknown callback id. By default the former are answered with "Unknown
+// callback: id" and the latter with an empty message.
+func (s *SlackBot) SetInteractionNotFound(handler InteractionCallbackFunc) {
+	s.interactionNotFound = handler
+}
+
+// SetEventNotFound sets the handler for events without registered handlers.
+// By default they are only logged.
+func (s *SlackBot) SetEventNotFound(handler CallbackEventFunc) {
+	s.eventNotFound = handler
+}
+
+func (s *SlackBot) fireCommandNotFound(command slack.SlashCommand, ctx *Context) slack.Message {
+
+	log.Debugf("Command %s not registered", command.Command)
+
+	if s.commandNotFound != nil {
+		return s.commandNotFound(command, ctx)
+	}
+
+	return slack.Message{Msg: slack.Msg{Text: fmt.Sprintf("Unknown command: %s %s", command.Command, command.Text)}}
+
+}
+
+func (s *SlackBot) fireInteractionNotFound(interactionCallback slack.InteractionCallback, callbackId string, ctx *Context) slack.Message {
+
+	if s.interactionNotFound != nil {
+		log.Debugf("Callback %s not found", callbackId)
+		return s.interactionNotFound(interactionCallback, ctx)
+	}
+
+	if s.hasInteractionType(interactionCallback.Type) {
+		log.Debugf("Callback %s not found", callbackId)
+		return slack.Message{}
+	}
+
+	log.Debugf("Unknown callback: %s", callbackId)
+	return slack.Message{Msg: slack.Msg{Text: fmt.Sprintf("Unknown callback: %s", callbackId)}}
+
+}
+
+func (s *SlackBot) fireEventNotFound(eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) {
+
+	log.Debugf("Event %s not registered", eventsAPIEvent.InnerEvent.Type)
+
+	if s.eventNotFound != nil {
+		s.eventNotFound(eventsAPIEvent, ctx)
+	}
+
+}
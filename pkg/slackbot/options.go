@@ -0,0 +1,96 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"net/http"
+)
+
+// OptionsResponse is the answer to a block_suggestion payload sent for an
+// external_select or multi_external_select. Fill either Options or
+// OptionGroups; when OptionGroups is set it takes precedence.
+type OptionsResponse struct {
+	Options      []*slack.OptionBlockObject
+	OptionGroups []*slack.OptionGroupBlockObject
+}
+
+func (o OptionsResponse) MarshalJSON() ([]byte, error) {
+
+	if o.OptionGroups != nil {
+		return json.Marshal(slack.OptionGroupsResponse{OptionGroups: o.OptionGroups})
+	}
+
+	// Slack expects an (empty) options list rather than an empty object.
+	options := o.Options
+	if options == nil {
+		options = []*slack.OptionBlockObject{}
+	}
+
+	return json.Marshal(struct {
+		Options []*slack.OptionBlockObject `json:"options"`
+	}{options})
+
+}
+
+// OptionsLoaderFunc receives the block_suggestion payload; suggestion.Value
+// holds what the user typed so far.
+type OptionsLoaderFunc func(suggestion slack.InteractionCallback, ctx *Context) OptionsResponse
+
+// RegisterOptionsLoader registers the function that loads the options of the
+// external select menu with the given action_id.
+func (s *SlackBot) RegisterOptionsLoader(actionID string, handler OptionsLoaderFunc) error {
+
+	if _, ok := s.registeredOptionsLoaders[actionID]; ok {
+		return fmt.Errorf("options loader '%s' already registered", actionID)
+	}
+
+	log.Debugf("Registering options loader: %s", actionID)
+	s.registeredOptionsLoaders[actionID] = handler
+
+	return nil
+
+}
+
+func (s *SlackBot) FireOptionsLoader(suggestion slack.InteractionCallback, ctx *Context) OptionsResponse {
+
+	var payload OptionsResponse
+
+	if loaderFunc, ok := s.registeredOptionsLoaders[suggestion.ActionID]; ok {
+		log.Debugf("Options loader %s found", suggestion.ActionID)
+		payload = loaderFunc(suggestion, ctx)
+	} else {
+		log.Debugf("Options loader %s not registered", suggestion.ActionID)
+	}
+
+	return payload
+
+}
+
+func (s *SlackBot) LoadOptionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	if err := s.VerifySignature(w, r); err != nil {
+		log.Errorf("Fail to verify SigningSecret: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	suggestion, err := slack.InteractionCallbackParse(r)
+	if err != nil {
+		log.Errorf("Could not parse block suggestion JSON: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ctx := s.newHTTPContext(w, r)
+	defer ctx.done()
+	// Dispatched like other interactions, so middleware runs as in socket
+	// mode.
+	response := s.fireInteraction(suggestion, ctx)
+
+	if !ctx.IsFinished() {
+		s.renderJSON(w, r, response)
+	}
+
+}
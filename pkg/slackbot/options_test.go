@@ -0,0 +1,123 @@
+package slackbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/slack-go/slack"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSigningSecret = "test-secret"
+
+// signedRequest returns a form POST signed like Slack does with
+// testSigningSecret.
+func signedRequest(path string, form url.Values) *http.Request {
+
+	body := form.Encode()
+	timestamp := fmt.Sprint(time.Now().Unix())
+
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	r := httptest.NewRequest("POST", path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return r
+
+}
+
+func suggestionForm(actionID, value string) url.Values {
+	payload, _ := json.Marshal(map[string]string{"type": "block_suggestion", "action_id": actionID, "value": value})
+	return url.Values{"payload": {string(payload)}}
+}
+
+func option(value string) *slack.OptionBlockObject {
+	return slack.NewOptionBlockObject(value, slack.NewTextBlockObject(slack.PlainTextType, value, false, false), nil)
+}
+
+func TestOptionsResponseJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		response OptionsResponse
+		want     string
+	}{
+		{"nil options", OptionsResponse{}, `{"options":[]}`},
+		{"options", OptionsResponse{Options: []*slack.OptionBlockObject{option("a")}}, `{"options":[{"text":{"type":"plain_text","text":"a","emoji":false},"value":"a"}]}`},
+		{"groups take precedence", OptionsResponse{
+			Options:      []*slack.OptionBlockObject{option("a")},
+			OptionGroups: []*slack.OptionGroupBlockObject{slack.NewOptionGroupBlockElement(slack.NewTextBlockObject(slack.PlainTextType, "G", false, false), option("b"))},
+		}, `{"option_groups":[{"label":{"type":"plain_text","text":"G","emoji":false},"options":[{"text":{"type":"plain_text","text":"b","emoji":false},"value":"b"}]}]}`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.response)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOptionsLoaderDispatch(t *testing.T) {
+	bot := NewSlackBot(testSigningSecret, "", "")
+	bot.RegisterOptionsLoader("pick", func(suggestion slack.InteractionCallback, ctx *Context) OptionsResponse {
+		return OptionsResponse{Options: []*slack.OptionBlockObject{option(suggestion.Value)}}
+	})
+	want := `{"options":[{"text":{"type":"plain_text","text":"ab","emoji":false},"value":"ab"}]}`
+
+	w := httptest.NewRecorder()
+	bot.LoadOptionsHandler(w, signedRequest("/slack/load-options", suggestionForm("pick", "ab")))
+	if got := strings.TrimSpace(w.Body.String()); w.Code != http.StatusOK || got != want {
+		t.Errorf("HTTP: got %d %s, want %s", w.Code, got, want)
+	}
+
+	// Socket mode acks the response of fireInteraction.
+	response := bot.fireInteraction(slack.InteractionCallback{Type: slack.InteractionTypeBlockSuggestion, ActionID: "pick", Value: "ab"}, &Context{})
+	if got, _ := json.Marshal(response); string(got) != want {
+		t.Errorf("socket: got %s, want %s", got, want)
+	}
+
+	response = bot.fireInteraction(slack.InteractionCallback{Type: slack.InteractionTypeBlockSuggestion, ActionID: "unknown"}, &Context{})
+	if got, _ := json.Marshal(response); string(got) != `{"options":[]}` {
+		t.Errorf("unknown loader: got %s", got)
+	}
+}
+
+func TestOptionsLoaderMiddleware(t *testing.T) {
+	bot := NewSlackBot(testSigningSecret, "", "")
+	bot.RegisterOptionsLoader("pick", func(suggestion slack.InteractionCallback, ctx *Context) OptionsResponse {
+		return OptionsResponse{Options: []*slack.OptionBlockObject{option("a")}}
+	})
+	var seen []string
+	bot.Use(func(next Handler) Handler {
+		return func(req *Request, ctx *Context) interface{} {
+			if req.Kind == RequestInteraction {
+				seen = append(seen, req.Name())
+			}
+			return next(req, ctx)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	bot.LoadOptionsHandler(w, signedRequest("/slack/load-options", suggestionForm("pick", "")))
+	bot.fireInteraction(slack.InteractionCallback{Type: slack.InteractionTypeBlockSuggestion, ActionID: "pick"}, &Context{})
+
+	if len(seen) != 2 || seen[0] != "pick" || seen[1] != "pick" {
+		t.Errorf("middleware should see the HTTP and socket options requests alike, got %v", seen)
+	}
+	if !strings.Contains(w.Body.String(), `"value":"a"`) {
+		t.Errorf("HTTP: got %s", w.Body.String())
+	}
+}
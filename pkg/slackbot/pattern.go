@@ -0,0 +1,139 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"regexp"
+	"strings"
+)
+
+var patternParamName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type callbackPattern struct {
+	pattern string
+	re      *regexp.Regexp
+	handler InteractionCallbackFunc
+}
+
+// compilePattern turns a callback id pattern into a regexp. {name} captures
+// one or more characters up to the next ':' or '/', * matches anything and
+// all other characters match themselves.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for rest := pattern; rest != ""; {
+		switch {
+		case rest[0] == '*':
+			expr.WriteString(".*")
+			rest = rest[1:]
+		case rest[0] == '{':
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("pattern '%s' has an unclosed {", pattern)
+			}
+			name := rest[1:end]
+			if !patternParamName.MatchString(name) {
+				return nil, fmt.Errorf("pattern '%s' has an invalid parameter name '%s'", pattern, name)
+			}
+			expr.WriteString("(?P<" + name + ">[^:/]+)")
+			rest = rest[end+1:]
+		default:
+			next := strings.IndexAny(rest, "*{")
+			if next < 0 {
+				next = len(rest)
+			}
+			expr.WriteString(regexp.QuoteMeta(rest[:next]))
+			rest = rest[next:]
+		}
+	}
+
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+
+}
+
+// RegisterInteractionPattern registers handler for every callback id that
+// matches pattern, like "approve:{id}" or "approve:*". The values captured by
+// {name} are available through ctx.CallbackParam.
+//
+// Callbacks registered with RegisterInteractionCallback for the exact id take
+// precedence; patterns and regexps are tried in registration order and the
+// first match wins.
+func (s *SlackBot) RegisterInteractionPattern(interactionType slack.InteractionType, pattern string, handler InteractionCallbackFunc) error {
+
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	return s.registerCallbackPattern(interactionType, pattern, re, handler)
+
+}
+
+// RegisterInteractionRegexp registers handler for every callback id matched
+// by re. The named groups of re are available through ctx.CallbackParam. See
+// RegisterInteractionPattern for the precedence rules.
+func (s *SlackBot) RegisterInteractionRegexp(interactionType slack.InteractionType, re *regexp.Regexp, handler InteractionCallbackFunc) error {
+	return s.registerCallbackPattern(interactionType, re.String(), re, handler)
+}
+
+func (s *SlackBot) registerCallbackPattern(interactionType slack.InteractionType, pattern string, re *regexp.Regexp, handler InteractionCallbackFunc) error {
+
+	for _, registered := range s.registeredCallbackPatterns[interactionType] {
+		if registered.pattern == pattern {
+			return fmt.Errorf("%s Callback pattern '%s' already registered", interactionType, pattern)
+		}
+	}
+
+	log.Debugf("Registering callback pattern: %s", pattern)
+	s.registeredCallbackPatterns[interactionType] = append(s.registeredCallbackPatterns[interactionType], callbackPattern{
+		pattern: pattern,
+		re:      re,
+		handler: handler,
+	})
+
+	return nil
+
+}
+
+// findInteractionCallback looks up the callback for callbackId, trying the
+// exact registrations before the patterns. The parameters captured by a
+// pattern are stored on ctx.
+func (s *SlackBot) findInteractionCallback(interactionType slack.InteractionType, callbackId string, ctx *Context) (InteractionCallbackFunc, bool) {
+
+	ctx.CallbackParams = nil
+
+	if callbackFunc, ok := s.registeredCallbacks[interactionType][callbackId]; ok {
+		return callbackFunc, true
+	}
+
+	for _, registered := range s.registeredCallbackPatterns[interactionType] {
+		match := registered.re.FindStringSubmatch(callbackId)
+		if match == nil {
+			continue
+		}
+
+		params := make(map[string]string)
+		for i, name := range registered.re.SubexpNames() {
+			if name != "" {
+				params[name] = match[i]
+			}
+		}
+		ctx.CallbackParams = params
+
+		log.Debugf("Callback %s matched pattern %s", callbackId, registered.pattern)
+		return registered.handler, true
+	}
+
+	return nil, false
+
+}
+
+func (s *SlackBot) hasInteractionType(interactionType slack.InteractionType) bool {
+	_, ok := s.registeredCallbacks[interactionType]
+	return ok || len(s.registeredCallbackPatterns[interactionType]) > 0
+}
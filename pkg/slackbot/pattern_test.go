@@ -0,0 +1,68 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"regexp"
+	"testing"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		id      string
+		match   bool
+	}{
+		{"approve:{id}", "approve:1234", true},
+		{"approve:{id}", "approve:", false},
+		{"approve:{id}", "approve:12:34", false},
+		{"approve:*", "approve:12:34", true},
+		{"a.b:{id}", "axb:1", false},
+		{"{action}:{id}", "reject:1234", true},
+	}
+
+	for _, tt := range tests {
+		re, err := compilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.id); got != tt.match {
+			t.Errorf("%s matching %s: got %v, want %v", tt.pattern, tt.id, got, tt.match)
+		}
+	}
+
+	for _, pattern := range []string{"approve:{id", "approve:{1d}"} {
+		if _, err := compilePattern(pattern); err == nil {
+			t.Errorf("%s should not compile", pattern)
+		}
+	}
+}
+
+func TestInteractionPatternPrecedence(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	handler := func(text string) InteractionCallbackFunc {
+		return func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+			return slack.Message{Msg: slack.Msg{Text: text + ":" + ctx.CallbackParam("id")}}
+		}
+	}
+
+	bot.RegisterInteractionPattern(slack.InteractionTypeBlockActions, "approve:{id}", handler("pattern"))
+	bot.RegisterInteractionRegexp(slack.InteractionTypeBlockActions, regexp.MustCompile(`^approve:(?P<id>.*)$`), handler("regexp"))
+	bot.RegisterInteractionCallback(slack.InteractionTypeBlockActions, "approve:all", handler("exact"))
+
+	tests := map[string]string{
+		"approve:all":   "exact:",
+		"approve:1234":  "pattern:1234",
+		"approve:12/34": "regexp:12/34",
+	}
+
+	for actionID, want := range tests {
+		payload := blockActionsPayload(&slack.BlockAction{ActionID: actionID})
+		if msg := bot.FireInteractiveCallback(payload, &Context{}); msg.Text != want {
+			t.Errorf("%s: got %q, want %q", actionID, msg.Text, want)
+		}
+	}
+
+	if err := bot.RegisterInteractionPattern(slack.InteractionTypeBlockActions, "approve:{id}", handler("again")); err == nil {
+		t.Errorf("registering a pattern twice should fail")
+	}
+}
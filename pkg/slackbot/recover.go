@@ -0,0 +1,103 @@
+package slackbot
+
+import (
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"runtime/debug"
+)
+
+const defaultPanicMessage = "Sorry, something went wrong. Please try again later."
+
+// PanicFunc is called with the recovered value and stack of a panic in a
+// handler, e.g. to report it to an error tracker.
+type PanicFunc func(recovered interface{}, stack []byte, req *Request, ctx *Context)
+
+// SetPanicMessage sets the ephemeral message a user gets when the handler of
+// their slash command or interaction panics. An empty text sends none.
+func (s *SlackBot) SetPanicMessage(text string) {
+	s.panicMessage = text
+}
+
+// OnPanic sets the function that is called for every recovered panic, after
+// it is logged.
+func (s *SlackBot) OnPanic(handler PanicFunc) {
+	s.panicHook = handler
+}
+
+// reportPanic logs a recovered panic with its stack and passes it to the
+// OnPanic hook. Call it from the deferred function that recovered.
+func (s *SlackBot) reportPanic(recovered interface{}, req *Request, ctx *Context) {
+
+	stack := debug.Stack()
+	log.Errorf("Handler for %s panicked: %v\n%s", req.Name(), recovered, stack)
+
+	// Contexts built outside a transport, like in tests, have no bot.
+	if s == nil || s.panicHook == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("OnPanic hook panicked: %v", r)
+		}
+	}()
+
+	s.panicHook(recovered, stack, req, ctx)
+
+}
+
+// recoverPanics is the outermost middleware of every dispatch. It turns a
+// panic into the panic message, so the request is still answered and
+// acknowledged.
+func (s *SlackBot) recoverPanics(next Handler) Handler {
+	return func(req *Request, ctx *Context) (response interface{}) {
+
+		defer func() {
+			if r := recover(); r != nil {
+				s.reportPanic(r, req, ctx)
+				response = s.panicResponse(req, ctx)
+			}
+		}()
+
+		return next(req, ctx)
+
+	}
+}
+
+// panicResponse returns the response to a request whose handler panicked. A
+// slash command gets the panic message as its response; an interaction, whose
+// response Slack does not show, gets it posted to its response_url; an
+// options request gets an empty list.
+func (s *SlackBot) panicResponse(req *Request, ctx *Context) interface{} {
+
+	if req.Kind == RequestInteraction && req.Interaction.Type == slack.InteractionTypeBlockSuggestion {
+		// An options request has no response_url; an empty list ends the
+		// loading state of the menu.
+		return OptionsResponse{}
+	}
+
+	if s.panicMessage == "" || req.Kind == RequestEvent {
+		return nil
+	}
+
+	if req.Kind == RequestCommand && !ctx.IsFinished() {
+		return ephemeralMessage(s.panicMessage)
+	}
+
+	s.respondPanic(ctx)
+
+	return nil
+
+}
+
+// respondPanic posts the panic message to the response_url of ctx, if any,
+// without waiting for it.
+func (s *SlackBot) respondPanic(ctx *Context) {
+
+	if s == nil || s.panicMessage == "" || ctx.responseURL == "" {
+		return
+	}
+
+	ctx.respondDetached(ephemeralMessage(s.panicMessage))
+
+}
@@ -0,0 +1,120 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"time"
+)
+
+// responseTimeout bounds a single post to a response_url.
+const responseTimeout = 10 * time.Second
+
+// Respond posts msg to the response_url of the slash command or interaction
+// that produced the context, over HTTP as well as in socket mode. It can be
+// called several times within the 30 minutes Slack accepts follow-ups. Set
+// msg.ResponseType to slack.ResponseTypeInChannel to show the reply to the
+// whole channel and msg.ReplaceOriginal to replace the original message; by
+// default the reply is ephemeral. Respond works after the handler returned,
+// e.g. from a goroutine; only Shutdown cancels it.
+func (c *Context) Respond(msg slack.Message) error {
+
+	if c.responseURL == "" {
+		return fmt.Errorf("no response_url available to respond to")
+	}
+
+	responseCtx, cancel := c.responseContext()
+	defer cancel()
+
+	return postResponse(responseCtx, c.responseURL, msg)
+
+}
+
+// respondDetached posts msg to the response_url without waiting for it, for
+// messages sent while the request is being finished.
+func (c *Context) respondDetached(msg slack.Message) {
+
+	responseCtx, cancel := c.responseContext()
+	responseURL := c.responseURL
+
+	go func() {
+		defer cancel()
+		if err := postResponse(responseCtx, responseURL, msg); err != nil {
+			log.Errorf("Could not post to the response_url: %v", err)
+		}
+	}()
+
+}
+
+// responseContext returns the context for a post to the response_url. It
+// keeps the values of the request but not its cancellation or deadline, since
+// the request is often done before the post is.
+func (c *Context) responseContext() (context.Context, context.CancelFunc) {
+
+	ctx, release := c.bot.withShutdown(context.WithoutCancel(c.Context()))
+	ctx, cancel := context.WithTimeout(ctx, responseTimeout)
+
+	return ctx, func() {
+		cancel()
+		release()
+	}
+
+}
+
+// useInteraction makes Respond and OpenView use the response_url and
+// trigger_id of the interaction.
+func (c *Context) useInteraction(interactionCallback slack.InteractionCallback) {
+
+	c.triggerID = interactionCallback.TriggerID
+	c.responseURL = interactionCallback.ResponseURL
+	if c.responseURL == "" && len(interactionCallback.ResponseURLs) > 0 {
+		// Modals only get a response_url when an input block asks for one.
+		c.responseURL = interactionCallback.ResponseURLs[0].ResponseURL
+	}
+
+}
+
+func (c *Context) RespondEphemeral(msg slack.Message) error {
+	msg.ResponseType = slack.ResponseTypeEphemeral
+	return c.Respond(msg)
+}
+
+func (c *Context) RespondInChannel(msg slack.Message) error {
+	msg.ResponseType = slack.ResponseTypeInChannel
+	return c.Respond(msg)
+}
+
+// ReplaceOriginal replaces the message the interaction came from, e.g. the
+// message holding the clicked button.
+func (c *Context) ReplaceOriginal(msg slack.Message) error {
+	msg.ReplaceOriginal = true
+	return c.Respond(msg)
+}
+
+// DeleteOriginal deletes the message the interaction came from.
+func (c *Context) DeleteOriginal() error {
+	return c.Respond(slack.Message{Msg: slack.Msg{DeleteOriginal: true}})
+}
+
+func postResponse(ctx context.Context, responseURL string, msg slack.Message) error {
+
+	webhookMessage := slack.WebhookMessage{
+		Text:            msg.Text,
+		Attachments:     msg.Attachments,
+		ThreadTimestamp: msg.ThreadTimestamp,
+		ResponseType:    msg.ResponseType,
+		ReplaceOriginal: msg.ReplaceOriginal,
+		DeleteOriginal:  msg.DeleteOriginal,
+	}
+	if len(msg.Blocks.BlockSet) > 0 {
+		webhookMessage.Blocks = &msg.Blocks
+	}
+
+	return slack.PostWebhookContext(ctx, responseURL, &webhookMessage)
+
+}
+
+func isEmptyMessage(msg slack.Message) bool {
+	return msg.Text == "" && len(msg.Blocks.BlockSet) == 0 && len(msg.Attachments) == 0 && !msg.DeleteOriginal
+}
@@ -0,0 +1,100 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"github.com/slack-go/slack"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func webhookServer(t *testing.T) (*httptest.Server, chan slack.WebhookMessage) {
+
+	received := make(chan slack.WebhookMessage, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.WebhookMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("could not decode response: %v", err)
+		}
+		received <- msg
+	}))
+	t.Cleanup(server.Close)
+
+	return server, received
+
+}
+
+func TestRespondFlags(t *testing.T) {
+	server, received := webhookServer(t)
+
+	ctx := &Context{}
+	ctx.useInteraction(slack.InteractionCallback{ResponseURL: server.URL})
+	text := slack.Message{Msg: slack.Msg{Text: "hi"}}
+
+	tests := []struct {
+		name    string
+		respond func() error
+		want    slack.WebhookMessage
+	}{
+		{"ephemeral", func() error { return ctx.RespondEphemeral(text) }, slack.WebhookMessage{Text: "hi", ResponseType: slack.ResponseTypeEphemeral}},
+		{"in channel", func() error { return ctx.RespondInChannel(text) }, slack.WebhookMessage{Text: "hi", ResponseType: slack.ResponseTypeInChannel}},
+		{"replace", func() error { return ctx.ReplaceOriginal(text) }, slack.WebhookMessage{Text: "hi", ReplaceOriginal: true}},
+		{"delete", func() error { return ctx.DeleteOriginal() }, slack.WebhookMessage{DeleteOriginal: true}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.respond(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := <-received
+		if got.Text != tt.want.Text || got.ResponseType != tt.want.ResponseType || got.ReplaceOriginal != tt.want.ReplaceOriginal || got.DeleteOriginal != tt.want.DeleteOriginal {
+			t.Errorf("%s: posted %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRespondURLFallback(t *testing.T) {
+	server, received := webhookServer(t)
+
+	ctx := &Context{}
+	callback := slack.InteractionCallback{Type: slack.InteractionTypeViewSubmission}
+	callback.ResponseURLs = []slack.ViewSubmissionCallbackResponseURL{{ResponseURL: server.URL}}
+	ctx.useInteraction(callback)
+	if err := ctx.RespondInChannel(slack.Message{Msg: slack.Msg{Text: "submitted"}}); err != nil {
+		t.Fatalf("respond failed: %v", err)
+	}
+	if got := <-received; got.Text != "submitted" {
+		t.Errorf("posted %q to the response_urls fallback", got.Text)
+	}
+
+	if err := (&Context{}).Respond(slack.Message{}); err == nil {
+		t.Errorf("respond without a response_url should fail")
+	}
+}
+
+func TestRespondAfterHandler(t *testing.T) {
+	server, received := webhookServer(t)
+
+	bot := NewSlackBot("", "", "")
+	followUp := make(chan error, 1)
+	bot.RegisterInteractionCallback(slack.InteractionTypeBlockActions, "later", func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		go func() {
+			<-ctx.Context().Done()
+			followUp <- ctx.Respond(slack.Message{Msg: slack.Msg{Text: "done"}})
+		}()
+		return slack.Message{}
+	})
+
+	ctx := bot.newHTTPContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/slack/actions", nil))
+	callback := blockActionsPayload(&slack.BlockAction{ActionID: "later"})
+	callback.ResponseURL = server.URL
+	bot.fireInteraction(callback, ctx)
+	ctx.done()
+
+	if err := <-followUp; err != nil {
+		t.Fatalf("respond after the handler returned: %v", err)
+	}
+	if got := <-received; got.Text != "done" {
+		t.Errorf("posted %q", got.Text)
+	}
+}
@@ -0,0 +1,63 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+)
+
+// Shortcut is the invocation of a global shortcut. Use TriggerID, or
+// ctx.OpenView, to open a modal within 3 seconds.
+type Shortcut struct {
+	CallbackID string
+	TriggerID  string
+	User       slack.User
+	Team       slack.Team
+}
+
+// MessageShortcut is the invocation of a message shortcut on Message.
+// ThreadTimestamp is the thread the message belongs to, or the message
+// itself when it is not in a thread, so replies can go to its thread.
+type MessageShortcut struct {
+	Shortcut
+	Message         slack.Message
+	Channel         slack.Channel
+	ThreadTimestamp string
+}
+
+type GlobalShortcutFunc func(shortcut Shortcut, ctx *Context)
+type MessageShortcutFunc func(shortcut MessageShortcut, ctx *Context)
+
+func newShortcut(callback slack.InteractionCallback) Shortcut {
+	return Shortcut{
+		CallbackID: callback.CallbackID,
+		TriggerID:  callback.TriggerID,
+		User:       callback.User,
+		Team:       callback.Team,
+	}
+}
+
+// RegisterGlobalShortcut registers the handler of the global shortcut with the
+// given callback_id. Shortcuts are acknowledged without a payload.
+func (s *SlackBot) RegisterGlobalShortcut(callbackID string, handler GlobalShortcutFunc) error {
+	return s.RegisterInteractionCallback(slack.InteractionTypeShortcut, callbackID, func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		handler(newShortcut(callback), ctx)
+		return slack.Message{}
+	})
+}
+
+// RegisterMessageShortcut registers the handler of the message shortcut with
+// the given callback_id. Shortcuts are acknowledged without a payload.
+func (s *SlackBot) RegisterMessageShortcut(callbackID string, handler MessageShortcutFunc) error {
+	return s.RegisterInteractionCallback(slack.InteractionTypeMessageAction, callbackID, func(callback slack.InteractionCallback, ctx *Context) slack.Message {
+		shortcut := MessageShortcut{
+			Shortcut:        newShortcut(callback),
+			Message:         callback.Message,
+			Channel:         callback.Channel,
+			ThreadTimestamp: callback.Message.ThreadTimestamp,
+		}
+		if shortcut.ThreadTimestamp == "" {
+			shortcut.ThreadTimestamp = callback.Message.Timestamp
+		}
+		handler(shortcut, ctx)
+		return slack.Message{}
+	})
+}
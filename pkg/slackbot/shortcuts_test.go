@@ -0,0 +1,75 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"github.com/slack-go/slack"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMessageShortcutThread(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	var got MessageShortcut
+	bot.RegisterMessageShortcut("quote", func(shortcut MessageShortcut, ctx *Context) {
+		got = shortcut
+	})
+
+	tests := []struct {
+		name    string
+		message slack.Msg
+		want    string
+	}{
+		{"top-level message", slack.Msg{Timestamp: "1.000"}, "1.000"},
+		{"thread reply", slack.Msg{Timestamp: "2.000", ThreadTimestamp: "1.000"}, "1.000"},
+	}
+
+	for _, tt := range tests {
+		payload := slack.InteractionCallback{Type: slack.InteractionTypeMessageAction, CallbackID: "quote", TriggerID: "T1"}
+		payload.Message = slack.Message{Msg: tt.message}
+		if response := bot.fireInteraction(payload, &Context{}); response != nil {
+			t.Errorf("%s: shortcuts should be acked without payload, got %#v", tt.name, response)
+		}
+		if got.ThreadTimestamp != tt.want || got.TriggerID != "T1" {
+			t.Errorf("%s: got thread %q and trigger %q", tt.name, got.ThreadTimestamp, got.TriggerID)
+		}
+	}
+}
+
+func TestGlobalShortcutOpenView(t *testing.T) {
+	triggerIDs := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			TriggerID string `json:"trigger_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		triggerIDs <- request.TriggerID
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	bot := NewSlackBot("", "", "")
+	bot.api = slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))
+	bot.RegisterGlobalShortcut("new_ticket", func(shortcut Shortcut, ctx *Context) {
+		if _, err := ctx.OpenView(slack.ModalViewRequest{Type: slack.VTModal}); err != nil {
+			t.Errorf("open view failed: %v", err)
+		}
+	})
+
+	ctx := bot.newHTTPContext(httptest.NewRecorder(), httptest.NewRequest("POST", "/slack/actions", nil))
+	defer ctx.done()
+	payload := slack.InteractionCallback{Type: slack.InteractionTypeShortcut, CallbackID: "new_ticket", TriggerID: "T42"}
+	if response := bot.fireInteraction(payload, ctx); response != nil {
+		t.Errorf("shortcuts should be acked without payload, got %#v", response)
+	}
+
+	select {
+	case triggerID := <-triggerIDs:
+		if triggerID != "T42" {
+			t.Errorf("views.open got trigger_id %q", triggerID)
+		}
+	default:
+		t.Errorf("the shortcut did not open a view")
+	}
+}
@@ -1,6 +1,7 @@
 package slackbot
 
 import (
+	"context"
 	"fmt"
 	"github.com/humsie/log"
 	"github.com/slack-go/slack"
@@ -18,9 +19,35 @@ type SlackBot struct {
 		slackDebug bool
 	}
 
-	registeredCommands  map[string]CommandFunc
-	registeredCallbacks map[slack.InteractionType]map[string]InteractionCallbackFunc
-	registeredEvents    map[slackevents.EventsAPIType]CallbackEventFunc
+	registeredCommands         map[string]*Command
+	registeredCallbacks        map[slack.InteractionType]map[string]InteractionCallbackFunc
+	registeredCallbackPatterns map[slack.InteractionType][]callbackPattern
+	registeredEvents           map[slackevents.EventsAPIType][]CallbackEventFunc
+	registeredOptionsLoaders   map[string]OptionsLoaderFunc
+	registeredBlockActions     map[blockActionKey]BlockActionFunc
+	registeredViewSubmissions  map[string]ViewSubmissionFunc
+	registeredViewClosed       map[string]ViewClosedFunc
+	registeredWizards          map[string]*wizard
+	registeredHears            []hears
+	middleware                 []Middleware
+	panicMessage               string
+	panicHook                  PanicFunc
+	errorRenderer              ErrorRenderer
+	commandNotFound            CommandFunc
+	interactionNotFound        InteractionCallbackFunc
+	eventNotFound              CallbackEventFunc
+
+	blockActionRouting BlockActionRouting
+	eventDispatch      EventDispatch
+	eventStore         EventStore
+	ackRetries         bool
+	eventQueue         *eventQueue
+	socketMaxInFlight  int
+	socketOrdering     SocketOrdering
+	botIdentity        botIdentity
+
+	baseContext context.Context
+	cancelBase  context.CancelFunc
 
 	api    *slack.Client
 	socket *socketmode.Client
@@ -59,8 +86,7 @@ func (s *SlackBot) RegisterCommand(command string, handler CommandFunc) error {
 		return fmt.Errorf("command '%s' already registered", command)
 	}
 
-	log.Debugf("Registering command: %s", command)
-	s.registeredCommands[command] = handler
+	s.Command(command).Handle(handler)
 
 	return nil
 
@@ -83,14 +109,17 @@ func (s *SlackBot) RegisterInteractionCallback(interactionType slack.Interaction
 
 }
 
+// RegisterCallbackEvent adds a handler for the event type. Any number of
+// handlers can be registered for the same type; see SetEventDispatch for how
+// they are run.
 func (s *SlackBot) RegisterCallbackEvent(event slackevents.EventsAPIType, handler CallbackEventFunc) error {
 
-	if _, ok := s.registeredEvents[event]; ok {
-		return fmt.Errorf("event '%s' already registered", event)
+	if handler == nil {
+		return fmt.Errorf("event '%s' needs a handler", event)
 	}
 
 	log.Debugf("Registering event: %s", event)
-	s.registeredEvents[event] = handler
+	s.registeredEvents[event] = append(s.registeredEvents[event], handler)
 
 	return nil
 
@@ -100,9 +129,25 @@ func (s *SlackBot) RegisterCallbackEvent(event slackevents.EventsAPIType, handle
 
 func (s *SlackBot) Setup() {
 
-	s.registeredCommands = make(map[string]CommandFunc)
+	s.registeredCommands = make(map[string]*Command)
 	s.registeredCallbacks = make(map[slack.InteractionType]map[string]InteractionCallbackFunc)
-	s.registeredEvents = make(map[slackevents.EventsAPIType]CallbackEventFunc)
+	s.registeredEvents = make(map[slackevents.EventsAPIType][]CallbackEventFunc)
+	s.registeredOptionsLoaders = make(map[string]OptionsLoaderFunc)
+	s.registeredBlockActions = make(map[blockActionKey]BlockActionFunc)
+	s.registeredCallbackPatterns = make(map[slack.InteractionType][]callbackPattern)
+	s.registeredViewSubmissions = make(map[string]ViewSubmissionFunc)
+	s.registeredViewClosed = make(map[string]ViewClosedFunc)
+	s.registeredWizards = make(map[string]*wizard)
+
+	if s.baseContext == nil {
+		s.baseContext, s.cancelBase = context.WithCancel(context.Background())
+	}
+	if s.panicMessage == "" {
+		s.panicMessage = defaultPanicMessage
+	}
+	if s.eventStore == nil {
+		s.eventStore = NewMemoryEventStore(defaultEventTTL, defaultEventMaxSize)
+	}
 
 	apiOptions := []slack.Option{}
 	apiOptions = append(apiOptions, slack.OptionDebug(s.config.slackDebug))
@@ -126,58 +171,115 @@ func (s *SlackBot) Setup() {
 
 func (s *SlackBot) FireSlashCommand(command slack.SlashCommand, ctx *Context) slack.Message {
 
+	ctx.responseURL = command.ResponseURL
+	ctx.triggerID = command.TriggerID
+
+	handler := s.dispatchHandler(func(req *Request, ctx *Context) interface{} {
+		return s.fireSlashCommand(*req.Command, ctx)
+	})
+
+	return messageResponse(handler(&Request{Kind: RequestCommand, Command: &command}, ctx))
+
+}
+
+func (s *SlackBot) fireSlashCommand(command slack.SlashCommand, ctx *Context) slack.Message {
+
 	var payload slack.Message
 
-	if commandFunc, ok := s.registeredCommands[command.Command]; ok {
-		log.Debugln(command.Command, " found")
-		payload = commandFunc(command, ctx)
+	if cmd, ok := s.registeredCommands[command.Command]; ok {
+		args, err := splitArgs(command.Text)
+		if err != nil {
+			// Only commands that parse params need balanced quotes; others
+			// get the words as typed.
+			args = strings.Fields(command.Text)
+			if cmd.selected(args).hasParams() {
+				return ephemeralMessage(fmt.Sprintf("%s: %v", command.Command, err))
+			}
+		}
+		payload = cmd.dispatch(command, args, ctx)
 	} else {
-		payload.Msg = slack.Msg{Text: fmt.Sprintf("Unknown command: %s %s", command.Command, command.Text)}
+		payload = s.fireCommandNotFound(command, ctx)
 	}
 	return payload
 
 }
 
+// fireInteraction runs an interaction payload through the middleware and
+// returns the response for the HTTP body or socket ack.
+func (s *SlackBot) fireInteraction(interactionCallback slack.InteractionCallback, ctx *Context) interface{} {
+
+	ctx.useInteraction(interactionCallback)
+
+	handler := s.dispatchHandler(func(req *Request, ctx *Context) interface{} {
+		return s.dispatchInteraction(*req.Interaction, ctx)
+	})
+
+	return handler(&Request{Kind: RequestInteraction, Interaction: &interactionCallback}, ctx)
+
+}
+
+// dispatchInteraction picks the dispatcher of the payload type. Payload types
+// that are not answered with a message, like block_suggestion, get their own
+// dispatcher.
+func (s *SlackBot) dispatchInteraction(interactionCallback slack.InteractionCallback, ctx *Context) interface{} {
+
+	switch {
+	case interactionCallback.Type == slack.InteractionTypeBlockSuggestion:
+		return s.FireOptionsLoader(interactionCallback, ctx)
+	case interactionCallback.Type == slack.InteractionTypeViewSubmission && s.hasViewSubmission(interactionCallback.View.CallbackID):
+		if response := s.FireViewSubmission(interactionCallback, ctx); response != nil {
+			return response
+		}
+		// An empty response closes the modal.
+		return nil
+	case interactionCallback.Type == slack.InteractionTypeViewClosed && s.hasViewClosed(interactionCallback.View.CallbackID):
+		s.FireViewClosed(interactionCallback, ctx)
+		return nil
+	case interactionCallback.Type == slack.InteractionTypeShortcut || interactionCallback.Type == slack.InteractionTypeMessageAction:
+		// Slack ignores the payload of a shortcut ack.
+		s.fireInteractiveCallback(interactionCallback, ctx)
+		return nil
+	default:
+		return s.fireInteractiveCallback(interactionCallback, ctx)
+	}
+
+}
+
+// FireInteractiveCallback dispatches an interaction payload the way the HTTP
+// and socket mode transports do, through the middleware and panic recovery.
+// It returns the response when that is a message; the responses to view
+// submissions and options requests are only sent by the transports.
 func (s *SlackBot) FireInteractiveCallback(interactionCallback slack.InteractionCallback, ctx *Context) slack.Message {
+	return messageResponse(s.fireInteraction(interactionCallback, ctx))
+}
+
+func (s *SlackBot) fireInteractiveCallback(interactionCallback slack.InteractionCallback, ctx *Context) slack.Message {
 
 	var payload slack.Message
 
 	callbackId := interactionCallback.CallbackID
 
-	if interactionCallback.Type == slack.InteractionTypeViewSubmission {
+	ctx.useInteraction(interactionCallback)
+
+	if interactionCallback.Type == slack.InteractionTypeViewSubmission || interactionCallback.Type == slack.InteractionTypeViewClosed {
 		callbackId = interactionCallback.View.CallbackID
 	}
 
 	if interactionCallback.Type == slack.InteractionTypeBlockActions {
-		callbackId = interactionCallback.Value
 
-		if len(interactionCallback.ActionCallback.BlockActions) > 0 {
-			log.Debugln("BlockActions")
-			callbackId = interactionCallback.ActionCallback.BlockActions[0].Value
-			if callbackId == "" {
-				callbackId = interactionCallback.ActionCallback.BlockActions[0].SelectedOption.Value
-			}
+		if payload, ok := s.fireBlockActions(interactionCallback, ctx); ok {
+			return payload
 		}
 
-		if len(interactionCallback.ActionCallback.AttachmentActions) > 0 {
-			log.Debugln("AttachmentActions")
-			callbackId = interactionCallback.ActionCallback.AttachmentActions[0].Value
-		}
+		callbackId = s.blockActionsCallbackID(interactionCallback)
 
 	}
 
-	if callbacks, ok := s.registeredCallbacks[interactionCallback.Type]; ok {
-
-		if callbackFunc, ok := callbacks[callbackId]; ok {
-			log.Debugf("Callback %s found", callbackId)
-			payload = callbackFunc(interactionCallback, ctx)
-		} else {
-			log.Debugf("Callback %s not found", callbackId)
-		}
-
+	if callbackFunc, ok := s.findInteractionCallback(interactionCallback.Type, callbackId, ctx); ok {
+		log.Debugf("Callback %s found", callbackId)
+		payload = callbackFunc(interactionCallback, ctx)
 	} else {
-		log.Debugf("Unknown callback: %s", callbackId)
-		payload.Msg = slack.Msg{Text: fmt.Sprintf("Unknown callback: %s", callbackId)}
+		payload = s.fireInteractionNotFound(interactionCallback, callbackId, ctx)
 	}
 
 	/*
@@ -193,14 +295,25 @@ func (s *SlackBot) FireInteractiveCallback(interactionCallback slack.Interaction
 
 func (s *SlackBot) FireCallbackEvent(eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) {
 
+	handler := s.dispatchHandler(func(req *Request, ctx *Context) interface{} {
+		s.fireCallbackEvent(*req.Event, ctx)
+		return nil
+	})
+
+	handler(&Request{Kind: RequestEvent, Event: &eventsAPIEvent}, ctx)
+
+}
+
+func (s *SlackBot) fireCallbackEvent(eventsAPIEvent slackevents.EventsAPIEvent, ctx *Context) {
+
 	innerEvent := eventsAPIEvent.InnerEvent
 
 	eventType := slackevents.EventsAPIType(innerEvent.Type)
 
-	if eventFunc, ok := s.registeredEvents[eventType]; ok {
-		eventFunc(eventsAPIEvent, ctx)
+	if eventFuncs, ok := s.registeredEvents[eventType]; ok {
+		s.runEventHandlers(eventFuncs, eventsAPIEvent, ctx)
 	} else {
-		log.Debugf("Event %s not registered", eventType)
+		s.fireEventNotFound(eventsAPIEvent, ctx)
 	}
 
 }
@@ -6,81 +6,112 @@ import (
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 	"github.com/slack-go/slack/socketmode"
+	"runtime/debug"
 )
 
 func (s *SlackBot) SocketListener() {
+
+	dispatch, stop := s.socketDispatcher()
+	defer stop()
+
 	for socketEvent := range s.socket.Events {
-		log.Debugln("Got event: ", socketEvent.Type)
-		socketContext := s.newSocketContext(&socketEvent)
-		var payload interface{}
-		var autoAck bool
-		payload = nil
-		autoAck = true
+		dispatch(socketEvent)
+	}
 
-		switch socketEvent.Type {
-		case socketmode.EventTypeConnecting:
-			log.Traceln("Connecting to Slack with socket Mode...")
-			autoAck = false
-		case socketmode.EventTypeConnectionError:
-			log.Traceln("Connection failed. Retrying later...")
-			autoAck = false
-		case socketmode.EventTypeConnected:
-			log.Traceln("Connected to Slack with socket Mode.")
-			autoAck = false
-		case socketmode.EventTypeEventsAPI:
-			var eventsAPIEvent slackevents.EventsAPIEvent
-			eventsAPIEvent, ok := socketEvent.Data.(slackevents.EventsAPIEvent)
-			if !ok {
-				continue
-			}
-			log.Debugf("Event received: %+v\n", eventsAPIEvent)
-
-			switch eventsAPIEvent.Type {
-			case slackevents.CallbackEvent:
-				s.FireCallbackEvent(eventsAPIEvent, socketContext)
-				autoAck = true
-			case slackevents.URLVerification:
-				log.Warnln("Url Verification event received")
-			case slackevents.AppRateLimited:
-				// AppRateLimited indicates your app's event subscriptions are being rate limited
-				log.Warnln("AppRateLimited event received")
-			default:
-				s.socket.Debugf("unsupported Events API event received")
-			}
+}
 
-		case socketmode.EventTypeInteractive:
-			callback, ok := socketEvent.Data.(slack.InteractionCallback)
-			if !ok {
-				continue
-			}
+// handleSocketEvent handles a single socket mode event and acknowledges it,
+// unless the handler already did.
+func (s *SlackBot) handleSocketEvent(socketEvent socketmode.Event) {
 
-			autoAck = true
-			payload = s.FireInteractiveCallback(callback, socketContext)
+	log.Debugln("Got event: ", socketEvent.Type)
+	socketContext := s.newSocketContext(&socketEvent)
+	defer socketContext.done()
 
-		case socketmode.EventTypeSlashCommand:
-			cmd, ok := socketEvent.Data.(slack.SlashCommand)
-			if !ok {
-				continue
+	// The dispatchers recover from panics in handlers; this keeps the
+	// listener alive for anything else and still acknowledges the event.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Handling socket event %s panicked: %v\n%s", socketEvent.Type, r, debug.Stack())
+			if socketEvent.Request != nil && !socketContext.IsFinished() {
+				s.socket.Ack(*socketEvent.Request)
 			}
+		}
+	}()
 
-			autoAck = true
-			payload = s.FireSlashCommand(cmd, socketContext)
+	var payload interface{}
+	var autoAck bool
+	payload = nil
+	autoAck = true
+
+	switch socketEvent.Type {
+	case socketmode.EventTypeConnecting:
+		log.Traceln("Connecting to Slack with socket Mode...")
+		autoAck = false
+	case socketmode.EventTypeConnectionError:
+		log.Traceln("Connection failed. Retrying later...")
+		autoAck = false
+	case socketmode.EventTypeConnected:
+		log.Traceln("Connected to Slack with socket Mode.")
+		autoAck = false
+	case socketmode.EventTypeEventsAPI:
+		var eventsAPIEvent slackevents.EventsAPIEvent
+		eventsAPIEvent, ok := socketEvent.Data.(slackevents.EventsAPIEvent)
+		if !ok {
+			return
+		}
+		log.Debugf("Event received: %+v\n", eventsAPIEvent)
 
-		case socketmode.EventTypeHello:
-			autoAck = false
-			//s.socket.Ack(*socketEvent.Request)
+		switch eventsAPIEvent.Type {
+		case slackevents.CallbackEvent:
+			autoAck = true
+			if !s.skipEvent(eventsAPIEvent, socketEvent.Request.RetryAttempt, socketEvent.Request.RetryReason) {
+				// Leave an event the queue has no room for unacknowledged,
+				// so Slack delivers it again.
+				autoAck = s.dispatchEvent(eventsAPIEvent, socketContext)
+			}
+		case slackevents.URLVerification:
+			log.Warnln("Url Verification event received")
+		case slackevents.AppRateLimited:
+			// AppRateLimited indicates your app's event subscriptions are being rate limited
+			log.Warnln("AppRateLimited event received")
 		default:
-			log.Errorf("Unexpected event type received: %s\n", socketEvent.Type)
-			autoAck = false
+			s.socket.Debugf("unsupported Events API event received")
+		}
+
+	case socketmode.EventTypeInteractive:
+		callback, ok := socketEvent.Data.(slack.InteractionCallback)
+		if !ok {
+			return
 		}
 
-		if autoAck && !socketContext.IsFinished() {
-			s.socket.Ack(
-				*socketEvent.Request,
-				payload,
-			)
+		autoAck = true
+		payload = s.fireInteraction(callback, socketContext)
+
+	case socketmode.EventTypeSlashCommand:
+		cmd, ok := socketEvent.Data.(slack.SlashCommand)
+		if !ok {
+			return
 		}
+
+		autoAck = true
+		payload = s.FireSlashCommand(cmd, socketContext)
+
+	case socketmode.EventTypeHello:
+		autoAck = false
+		//s.socket.Ack(*socketEvent.Request)
+	default:
+		log.Errorf("Unexpected event type received: %s\n", socketEvent.Type)
+		autoAck = false
 	}
+
+	if autoAck && !socketContext.IsFinished() {
+		s.socket.Ack(
+			*socketEvent.Request,
+			payload,
+		)
+	}
+
 }
 
 // RunSocket starts the socket-mode event listener and blocks while the socket
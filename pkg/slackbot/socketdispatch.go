@@ -0,0 +1,146 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"hash/fnv"
+	"sync"
+)
+
+// SocketOrdering selects which socket mode events SocketListener keeps in
+// order when it handles events concurrently.
+type SocketOrdering int
+
+const (
+	// SocketUnordered handles every event as soon as a slot is free.
+	SocketUnordered SocketOrdering = iota
+	// SocketOrderByChannel handles the events of a channel one after another,
+	// in the order they arrived.
+	SocketOrderByChannel
+	// SocketOrderByUser handles the events of a user one after another, in
+	// the order they arrived.
+	SocketOrderByUser
+)
+
+// socketShardBuffer is the number of events that can wait for a busy shard
+// before SocketListener stops reading new events.
+const socketShardBuffer = 32
+
+// SetSocketConcurrency makes SocketListener handle up to maxInFlight events at
+// the same time, so one slow handler no longer blocks the others. By default
+// events are handled one by one. When all slots are taken, SocketListener
+// waits before reading the next event.
+//
+// With SocketOrderByChannel or SocketOrderByUser, events with the same key are
+// handled by the same worker, in the order they arrived. Events without a
+// channel or user are spread over the workers.
+func (s *SlackBot) SetSocketConcurrency(maxInFlight int, ordering SocketOrdering) {
+	s.socketMaxInFlight = maxInFlight
+	s.socketOrdering = ordering
+}
+
+// socketDispatcher returns the function SocketListener passes every event to,
+// and a function that stops its workers when the listener ends.
+func (s *SlackBot) socketDispatcher() (dispatch func(socketmode.Event), stop func()) {
+
+	if s.socketMaxInFlight <= 1 {
+		return s.handleSocketEvent, func() {}
+	}
+
+	if s.socketOrdering == SocketUnordered {
+		slots := make(chan struct{}, s.socketMaxInFlight)
+		var wg sync.WaitGroup
+		dispatch = func(socketEvent socketmode.Event) {
+			slots <- struct{}{}
+			wg.Go(func() {
+				defer func() { <-slots }()
+				s.handleSocketEvent(socketEvent)
+			})
+		}
+		return dispatch, wg.Wait
+	}
+
+	shards := make([]chan socketmode.Event, s.socketMaxInFlight)
+	var wg sync.WaitGroup
+	for i := range shards {
+		shards[i] = make(chan socketmode.Event, socketShardBuffer)
+		wg.Go(func() {
+			for socketEvent := range shards[i] {
+				s.handleSocketEvent(socketEvent)
+			}
+		})
+	}
+
+	next := 0
+	dispatch = func(socketEvent socketmode.Event) {
+		if socketEvent.Request == nil {
+			// Connection events need no ack and no ordering.
+			s.handleSocketEvent(socketEvent)
+			return
+		}
+
+		shard := next
+		if key := socketEventKey(socketEvent, s.socketOrdering); key != "" {
+			hash := fnv.New32a()
+			hash.Write([]byte(key))
+			shard = int(hash.Sum32() % uint32(len(shards)))
+		} else {
+			next = (next + 1) % len(shards)
+		}
+
+		shards[shard] <- socketEvent
+	}
+
+	stop = func() {
+		for _, shard := range shards {
+			close(shard)
+		}
+		wg.Wait()
+	}
+
+	return dispatch, stop
+
+}
+
+// socketEventKey returns the channel or user id of a socket mode event, or an
+// empty string when the event has none.
+func socketEventKey(socketEvent socketmode.Event, ordering SocketOrdering) string {
+
+	byChannel := ordering == SocketOrderByChannel
+
+	switch data := socketEvent.Data.(type) {
+	case slack.SlashCommand:
+		if byChannel {
+			return data.ChannelID
+		}
+		return data.UserID
+	case slack.InteractionCallback:
+		if byChannel {
+			return data.Channel.ID
+		}
+		return data.User.ID
+	case slackevents.EventsAPIEvent:
+		// The inner events are typed per event type, so read the common
+		// channel and user fields from the raw payload.
+		var payload struct {
+			Event map[string]json.RawMessage `json:"event"`
+		}
+		if socketEvent.Request == nil || json.Unmarshal(socketEvent.Request.Payload, &payload) != nil {
+			return ""
+		}
+		field := "user"
+		if byChannel {
+			field = "channel"
+		}
+		// Fields that are not a string, like the channel object of
+		// channel_created, leave the key empty.
+		var key string
+		json.Unmarshal(payload.Event[field], &key)
+		return key
+	}
+
+	return ""
+
+}
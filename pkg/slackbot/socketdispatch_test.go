@@ -0,0 +1,76 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func slashCommandEvent(channel, text string) socketmode.Event {
+	return socketmode.Event{
+		Type:    socketmode.EventTypeSlashCommand,
+		Data:    slack.SlashCommand{Command: "/order", ChannelID: channel, UserID: "U1", Text: text},
+		Request: &socketmode.Request{EnvelopeID: channel + text},
+	}
+}
+
+func TestSocketEventKey(t *testing.T) {
+	command := slashCommandEvent("C1", "")
+	if key := socketEventKey(command, SocketOrderByChannel); key != "C1" {
+		t.Errorf("command channel key is %q", key)
+	}
+	if key := socketEventKey(command, SocketOrderByUser); key != "U1" {
+		t.Errorf("command user key is %q", key)
+	}
+
+	event := socketmode.Event{
+		Type:    socketmode.EventTypeEventsAPI,
+		Data:    slackevents.EventsAPIEvent{},
+		Request: &socketmode.Request{Payload: json.RawMessage(`{"event":{"type":"message","channel":"C2","user":"U2"}}`)},
+	}
+	if key := socketEventKey(event, SocketOrderByChannel); key != "C2" {
+		t.Errorf("event channel key is %q", key)
+	}
+
+	event.Request.Payload = json.RawMessage(`{"event":{"type":"channel_created","channel":{"id":"C3"}}}`)
+	if key := socketEventKey(event, SocketOrderByChannel); key != "" {
+		t.Errorf("channel object should not give a key, got %q", key)
+	}
+}
+
+func TestSocketOrderByChannel(t *testing.T) {
+	bot := NewSlackBot("", "xoxb-test", "xapp-test")
+	bot.SetSocketConcurrency(4, SocketOrderByChannel)
+
+	var mu sync.Mutex
+	handled := make(map[string][]string)
+	bot.RegisterCommand("/order", func(command slack.SlashCommand, ctx *Context) slack.Message {
+		if command.Text == "0" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled[command.ChannelID] = append(handled[command.ChannelID], command.Text)
+		return slack.Message{}
+	})
+
+	dispatch, stop := bot.socketDispatcher()
+	for i := 0; i < 5; i++ {
+		dispatch(slashCommandEvent("C1", fmt.Sprint(i)))
+		dispatch(slashCommandEvent("C2", fmt.Sprint(i)))
+	}
+	stop()
+
+	want := []string{"0", "1", "2", "3", "4"}
+	for _, channel := range []string{"C1", "C2"} {
+		if !reflect.DeepEqual(handled[channel], want) {
+			t.Errorf("%s handled in order %v, want %v", channel, handled[channel], want)
+		}
+	}
+}
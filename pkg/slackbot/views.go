@@ -0,0 +1,99 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+)
+
+// OpenView opens a modal with the trigger_id of the slash command, shortcut or
+// interaction that produced the context. Slack accepts a trigger_id for 3
+// seconds.
+func (c *Context) OpenView(view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+
+	if c.triggerID == "" {
+		return nil, fmt.Errorf("no trigger_id available to open a view with")
+	}
+
+	return c.Api.OpenViewContext(c.Context(), c.triggerID, view)
+
+}
+
+// ViewSubmissionFunc handles the submission of a modal. Return nil to close
+// the modal, or a response made with slack.NewErrorsViewSubmissionResponse,
+// slack.NewUpdateViewSubmissionResponse, slack.NewPushViewSubmissionResponse
+// or slack.NewClearViewSubmissionResponse.
+type ViewSubmissionFunc func(callback slack.InteractionCallback, ctx *Context) *slack.ViewSubmissionResponse
+
+// RegisterViewSubmission registers the handler for submissions of the modal
+// with the given callback_id. It takes precedence over a view_submission
+// callback registered with RegisterInteractionCallback.
+func (s *SlackBot) RegisterViewSubmission(callbackID string, handler ViewSubmissionFunc) error {
+
+	if _, ok := s.registeredViewSubmissions[callbackID]; ok {
+		return fmt.Errorf("view submission '%s' already registered", callbackID)
+	}
+
+	log.Debugf("Registering view submission: %s", callbackID)
+	s.registeredViewSubmissions[callbackID] = handler
+
+	return nil
+
+}
+
+func (s *SlackBot) FireViewSubmission(interactionCallback slack.InteractionCallback, ctx *Context) *slack.ViewSubmissionResponse {
+
+	callbackId := interactionCallback.View.CallbackID
+	ctx.useInteraction(interactionCallback)
+
+	if viewFunc, ok := s.registeredViewSubmissions[callbackId]; ok {
+		log.Debugf("View submission %s found", callbackId)
+		return viewFunc(interactionCallback, ctx)
+	}
+
+	log.Debugf("View submission %s not registered", callbackId)
+	return nil
+
+}
+
+func (s *SlackBot) hasViewSubmission(callbackId string) bool {
+	_, ok := s.registeredViewSubmissions[callbackId]
+	return ok
+}
+
+// ViewClosedFunc handles a modal the user closed without submitting it. Slack
+// only sends it for views with notify_on_close set.
+type ViewClosedFunc func(callback slack.InteractionCallback, ctx *Context)
+
+// RegisterViewClosed registers the handler that runs when the modal with the
+// given callback_id is closed, e.g. to clean up pending state.
+func (s *SlackBot) RegisterViewClosed(callbackID string, handler ViewClosedFunc) error {
+
+	if _, ok := s.registeredViewClosed[callbackID]; ok {
+		return fmt.Errorf("view closed '%s' already registered", callbackID)
+	}
+
+	log.Debugf("Registering view closed: %s", callbackID)
+	s.registeredViewClosed[callbackID] = handler
+
+	return nil
+
+}
+
+func (s *SlackBot) FireViewClosed(interactionCallback slack.InteractionCallback, ctx *Context) {
+
+	callbackId := interactionCallback.View.CallbackID
+
+	if viewFunc, ok := s.registeredViewClosed[callbackId]; ok {
+		log.Debugf("View closed %s found", callbackId)
+		viewFunc(interactionCallback, ctx)
+	} else {
+		log.Debugf("View closed %s not registered", callbackId)
+	}
+
+}
+
+func (s *SlackBot) hasViewClosed(callbackId string) bool {
+	_, ok := s.registeredViewClosed[callbackId]
+	return ok
+}
@@ -0,0 +1,188 @@
+package slackbot
+
+import (
+	"github.com/slack-go/slack"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func viewSubmissionPayload(callbackID string) slack.InteractionCallback {
+	return slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		View: slack.View{CallbackID: callbackID, State: &slack.ViewState{}},
+	}
+}
+
+func TestViewSubmissionResponse(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	bot.RegisterViewSubmission("signup", func(callback slack.InteractionCallback, ctx *Context) *slack.ViewSubmissionResponse {
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{"email": "Enter an email address"})
+	})
+	bot.RegisterViewSubmission("feedback", func(callback slack.InteractionCallback, ctx *Context) *slack.ViewSubmissionResponse {
+		return nil
+	})
+
+	response, ok := bot.fireInteraction(viewSubmissionPayload("signup"), &Context{}).(*slack.ViewSubmissionResponse)
+	if !ok || response.ResponseAction != slack.RAErrors || response.Errors["email"] == "" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	if response := bot.fireInteraction(viewSubmissionPayload("feedback"), &Context{}); response != nil {
+		t.Errorf("a nil view response should close the modal with an empty ack, got %#v", response)
+	}
+
+	if _, ok := bot.fireInteraction(viewSubmissionPayload("legacy"), &Context{}).(slack.Message); !ok {
+		t.Errorf("unregistered view submissions should fall back to the interaction callbacks")
+	}
+}
+
+func TestWizardSteps(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	var completed map[string]string
+
+	step := func(name string) WizardStep {
+		return WizardStep{
+			View: func(state *Callback, ctx *Context) slack.ModalViewRequest {
+				return slack.ModalViewRequest{Title: slack.NewTextBlockObject(slack.PlainTextType, name, false, false)}
+			},
+			Submit: func(callback slack.InteractionCallback, state *Callback, ctx *Context) error {
+				value := callback.View.State.Values[name][name].Value
+				if value == "" {
+					return ValidationErrors{name: "Required"}
+				}
+				state.Set(name, value)
+				return nil
+			},
+		}
+	}
+
+	err := bot.RegisterWizard("onboarding", Wizard{
+		Steps: []WizardStep{step("name"), step("team")},
+		Complete: func(callback slack.InteractionCallback, state *Callback, ctx *Context) *slack.ViewSubmissionResponse {
+			completed = map[string]string{"name": state.GetString("name"), "team": state.GetString("team")}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	w := bot.registeredWizards["onboarding"]
+	state := NewCallback()
+	submit := func(view slack.ModalViewRequest, name, value string) interface{} {
+		payload := viewSubmissionPayload(view.CallbackID)
+		payload.View.PrivateMetadata = view.PrivateMetadata
+		payload.View.State.Values = map[string]map[string]slack.BlockAction{name: {name: {Value: value}}}
+		return bot.fireInteraction(payload, &Context{})
+	}
+
+	first := w.view(0, state, &Context{})
+	if first.Submit.Text != "Next" || len(first.Blocks.BlockSet) != 0 {
+		t.Errorf("first step should have a next button and no back button: %+v", first)
+	}
+
+	if response := submit(first, "name", "").(*slack.ViewSubmissionResponse); response.ResponseAction != slack.RAErrors {
+		t.Errorf("invalid input should keep the step open, got %+v", response)
+	}
+
+	response := submit(first, "name", "Jane").(*slack.ViewSubmissionResponse)
+	if response.ResponseAction != slack.RAUpdate || response.View.Title.Text != "team" || response.View.Submit.Text != "Done" {
+		t.Fatalf("expected the second step, got %+v", response)
+	}
+	if len(response.View.Blocks.BlockSet) != 1 {
+		t.Errorf("second step should have a back button")
+	}
+
+	if response := submit(*response.View, "team", "infra"); response != nil {
+		t.Errorf("completing should close the modal, got %#v", response)
+	}
+	if completed["name"] != "Jane" || completed["team"] != "infra" {
+		t.Errorf("unexpected completed state: %v", completed)
+	}
+	if _, err := FindCallback(state.Id.String()); err == nil {
+		t.Errorf("state should be removed after completion")
+	}
+}
+
+func TestViewClosed(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	closed := ""
+	bot.RegisterViewClosed("signup", func(callback slack.InteractionCallback, ctx *Context) {
+		closed = callback.View.CallbackID
+	})
+
+	payload := slack.InteractionCallback{Type: slack.InteractionTypeViewClosed, View: slack.View{CallbackID: "signup"}}
+	if response := bot.fireInteraction(payload, &Context{}); response != nil {
+		t.Errorf("view_closed should be acked without payload, got %#v", response)
+	}
+	if closed != "signup" {
+		t.Errorf("view closed handler was not called")
+	}
+}
+
+func TestWizardStepError(t *testing.T) {
+	bot := NewSlackBot("", "", "")
+	err := bot.RegisterWizard("export", Wizard{
+		Steps: []WizardStep{{
+			View: func(state *Callback, ctx *Context) slack.ModalViewRequest {
+				return slack.ModalViewRequest{Title: slack.NewTextBlockObject(slack.PlainTextType, "Export", false, false)}
+			},
+			Submit: func(callback slack.InteractionCallback, state *Callback, ctx *Context) error {
+				return UserErrorf("The export service is down")
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	state := NewCallback()
+	view := bot.registeredWizards["export"].view(0, state, &Context{})
+	payload := viewSubmissionPayload(view.CallbackID)
+	payload.View.PrivateMetadata = view.PrivateMetadata
+
+	response, ok := bot.fireInteraction(payload, &Context{}).(*slack.ViewSubmissionResponse)
+	if !ok || response.ResponseAction != slack.RAUpdate {
+		t.Fatalf("a failed step should show the error in the modal, got %#v", response)
+	}
+	section, ok := response.View.Blocks.BlockSet[0].(*slack.SectionBlock)
+	if !ok || section.Text.Text != "The export service is down" || response.View.Submit != nil || response.View.Title.Text != "Error" {
+		t.Errorf("unexpected error view: %+v", response.View)
+	}
+	if _, err := FindCallback(state.Id.String()); err == nil {
+		t.Errorf("state should be removed after a failed step")
+	}
+}
+
+func TestOpenWizardFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":false,"error":"expired_trigger_id"}`))
+	}))
+	defer server.Close()
+
+	bot := NewSlackBot("", "", "")
+	bot.api = slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"))
+	bot.RegisterWizard("export", Wizard{Steps: []WizardStep{{
+		View: func(state *Callback, ctx *Context) slack.ModalViewRequest {
+			return slack.ModalViewRequest{Title: slack.NewTextBlockObject(slack.PlainTextType, "Export", false, false)}
+		},
+	}}})
+
+	ctx := &Context{Api: bot.api}
+	state, err := bot.OpenWizard("export", "T1", nil, ctx)
+	if err == nil {
+		t.Fatalf("opening with an expired trigger_id should fail")
+	}
+	if _, err := FindCallback(state.Id.String()); err == nil {
+		t.Errorf("the state created for a wizard that did not open should be removed")
+	}
+
+	given := NewCallback()
+	bot.OpenWizard("export", "T1", given, ctx)
+	if _, err := FindCallback(given.Id.String()); err != nil {
+		t.Errorf("a state passed in by the caller should be kept")
+	}
+	CallbackStorage.Delete(given.Id.String())
+}
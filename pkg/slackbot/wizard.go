@@ -0,0 +1,258 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/humsie/log"
+	"github.com/slack-go/slack"
+	"strconv"
+	"strings"
+)
+
+const (
+	wizardCallbackPrefix = "slackbot_wizard:"
+	wizardBackActionID   = "slackbot_wizard_back"
+)
+
+// WizardStep is one modal page of a Wizard.
+type WizardStep struct {
+	// View builds the modal of the step, e.g. prefilled from state. The
+	// wizard sets its callback_id and private_metadata, a default submit
+	// label and, from the second step on, a back button.
+	View func(state *Callback, ctx *Context) slack.ModalViewRequest
+	// Submit reads the submitted values into state. Return ValidationErrors
+	// to show them in the modal and stay on the step. Any other error ends
+	// the run: the state is removed and the modal shows the error as
+	// rendered by the ErrorRenderer.
+	Submit func(callback slack.InteractionCallback, state *Callback, ctx *Context) error
+}
+
+// Wizard is a modal that spans several steps. The state of a wizard run is a
+// Callback, so it lives in CallbackStorage until the wizard completes or
+// GCCallback expires it.
+type Wizard struct {
+	Steps []WizardStep
+	// Complete is called after the last step is submitted. Its response
+	// answers that submission; nil closes the modal.
+	Complete func(callback slack.InteractionCallback, state *Callback, ctx *Context) *slack.ViewSubmissionResponse
+	// Cancel is called when the user closes the wizard before completing it.
+	// The state is removed afterwards.
+	Cancel func(callback slack.InteractionCallback, state *Callback, ctx *Context)
+}
+
+type wizard struct {
+	Wizard
+	id string
+}
+
+// RegisterWizard registers a wizard under id. Start it with OpenWizard.
+func (s *SlackBot) RegisterWizard(id string, w Wizard) error {
+
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("wizard '%s' has no steps", id)
+	}
+
+	if _, ok := s.registeredWizards[id]; ok {
+		return fmt.Errorf("wizard '%s' already registered", id)
+	}
+
+	registered := &wizard{Wizard: w, id: id}
+	if err := s.RegisterViewSubmission(wizardCallbackPrefix+id, registered.submit); err != nil {
+		return err
+	}
+	if err := s.RegisterViewClosed(wizardCallbackPrefix+id, registered.closed); err != nil {
+		return err
+	}
+
+	if len(s.registeredWizards) == 0 {
+		if err := s.RegisterBlockAction("", wizardBackActionID, s.wizardBack); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Registering wizard: %s", id)
+	s.registeredWizards[id] = registered
+
+	return nil
+
+}
+
+// OpenWizard opens the first step of the wizard with the given id using the
+// trigger_id of a slash command, shortcut or block action. Pass nil as state
+// to start with an empty Callback; the state of the run is returned.
+func (s *SlackBot) OpenWizard(id, triggerID string, state *Callback, ctx *Context) (*Callback, error) {
+
+	w, ok := s.registeredWizards[id]
+	if !ok {
+		return nil, fmt.Errorf("wizard '%s' not registered", id)
+	}
+
+	created := state == nil
+	if created {
+		state = NewCallback()
+	}
+
+	if _, err := ctx.Api.OpenViewContext(ctx.Context(), triggerID, w.view(0, state, ctx)); err != nil {
+		if created {
+			CallbackStorage.Delete(state.Id.String())
+		}
+		return state, fmt.Errorf("could not open wizard '%s': %w", id, err)
+	}
+
+	return state, nil
+
+}
+
+func (w *wizard) view(step int, state *Callback, ctx *Context) slack.ModalViewRequest {
+
+	view := w.Steps[step].View(state, ctx)
+
+	if view.Type == "" {
+		view.Type = slack.VTModal
+	}
+	view.CallbackID = wizardCallbackPrefix + w.id
+	view.NotifyOnClose = true
+	view.PrivateMetadata = fmt.Sprintf("%s:%d", state.Id.String(), step)
+
+	if view.Submit == nil {
+		label := "Next"
+		if step == len(w.Steps)-1 {
+			label = "Done"
+		}
+		view.Submit = slack.NewTextBlockObject(slack.PlainTextType, label, false, false)
+	}
+
+	if step > 0 {
+		back := slack.NewButtonBlockElement(wizardBackActionID, "", slack.NewTextBlockObject(slack.PlainTextType, "Back", false, false))
+		view.Blocks.BlockSet = append(view.Blocks.BlockSet, slack.NewActionBlock("", back))
+	}
+
+	return view
+
+}
+
+// load returns the state and step stored in the private_metadata of a
+// wizard view.
+func (w *wizard) load(metadata string) (*Callback, int, error) {
+
+	id, stepText, ok := strings.Cut(metadata, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid wizard metadata: %s", metadata)
+	}
+
+	step, err := strconv.Atoi(stepText)
+	if err != nil || step < 0 || step >= len(w.Steps) {
+		return nil, 0, fmt.Errorf("invalid wizard step: %s", stepText)
+	}
+
+	state, err := FindCallback(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return state, step, nil
+
+}
+
+func (w *wizard) submit(callback slack.InteractionCallback, ctx *Context) *slack.ViewSubmissionResponse {
+
+	state, step, err := w.load(callback.View.PrivateMetadata)
+	if err != nil {
+		log.Errorf("Wizard %s: %v", w.id, err)
+		return nil
+	}
+
+	if submit := w.Steps[step].Submit; submit != nil {
+		if err := submit(callback, state, ctx); err != nil {
+			if errs, ok := err.(ValidationErrors); ok {
+				return errs.Response()
+			}
+			return w.fail(err, state, callback, ctx)
+		}
+	}
+
+	if step+1 < len(w.Steps) {
+		view := w.view(step+1, state, ctx)
+		return slack.NewUpdateViewSubmissionResponse(&view)
+	}
+
+	CallbackStorage.Delete(state.Id.String())
+
+	if w.Complete != nil {
+		return w.Complete(callback, state, ctx)
+	}
+
+	return nil
+
+}
+
+// fail ends the run after a step failed with an error other than
+// ValidationErrors: the state is removed and the modal shows the error,
+// rendered like the errors of other handlers.
+func (w *wizard) fail(err error, state *Callback, callback slack.InteractionCallback, ctx *Context) *slack.ViewSubmissionResponse {
+
+	CallbackStorage.Delete(state.Id.String())
+
+	msg := ctx.bot.renderError(err, &Request{Kind: RequestInteraction, Interaction: &callback}, ctx)
+
+	blocks := msg.Blocks
+	if len(blocks.BlockSet) == 0 {
+		blocks.BlockSet = []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, msg.Text, false, false), nil, nil)}
+	}
+
+	// Slack rejects a modal without a title.
+	title := callback.View.Title
+	if title == nil {
+		title = slack.NewTextBlockObject(slack.PlainTextType, "Error", false, false)
+	}
+
+	view := slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  title,
+		Close:  slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false),
+		Blocks: blocks,
+	}
+
+	return slack.NewUpdateViewSubmissionResponse(&view)
+
+}
+
+func (w *wizard) closed(callback slack.InteractionCallback, ctx *Context) {
+
+	state, _, err := w.load(callback.View.PrivateMetadata)
+	if err != nil {
+		log.Debugf("Wizard %s closed: %v", w.id, err)
+		return
+	}
+
+	if w.Cancel != nil {
+		w.Cancel(callback, state, ctx)
+	}
+
+	CallbackStorage.Delete(state.Id.String())
+
+}
+
+func (s *SlackBot) wizardBack(action slack.BlockAction, callback slack.InteractionCallback, ctx *Context) slack.Message {
+
+	w, ok := s.registeredWizards[strings.TrimPrefix(callback.View.CallbackID, wizardCallbackPrefix)]
+	if !ok {
+		log.Errorf("Wizard back button used in unknown view: %s", callback.View.CallbackID)
+		return slack.Message{}
+	}
+
+	state, step, err := w.load(callback.View.PrivateMetadata)
+	if err != nil {
+		log.Errorf("Wizard %s: %v", w.id, err)
+		return slack.Message{}
+	}
+
+	if step > 0 {
+		_, err = ctx.Api.UpdateViewContext(ctx.Context(), w.view(step-1, state, ctx), "", callback.View.Hash, callback.View.ID)
+		if err != nil {
+			log.Errorf("Wizard %s: could not go back: %v", w.id, err)
+		}
+	}
+
+	return slack.Message{}
+
+}